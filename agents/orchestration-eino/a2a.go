@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
@@ -21,8 +22,10 @@ import (
 	"google.golang.org/adk/tool/functiontool"
 	"google.golang.org/genai"
 
+	"github.com/grokify/stats-agent-team/pkg/config"
 	"github.com/grokify/stats-agent-team/pkg/models"
 	"github.com/grokify/stats-agent-team/pkg/orchestration"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
 )
 
 // A2AServer represents the A2A protocol server for the Eino Orchestration Agent.
@@ -37,14 +40,36 @@ type A2AServer struct {
 
 // OrchestrationInput defines input for the orchestration tool
 type OrchestrationInput struct {
-	Topic            string `json:"topic" jsonschema:"description=The topic to find statistics for"`
-	MinVerifiedStats int    `json:"min_verified_stats" jsonschema:"description=Minimum verified statistics to return"`
-	MaxCandidates    int    `json:"max_candidates" jsonschema:"description=Maximum candidates to consider"`
-	ReputableOnly    bool   `json:"reputable_only" jsonschema:"description=Only use reputable sources"`
+	Topic            string   `json:"topic" jsonschema:"description=The topic to find statistics for"`
+	MinVerifiedStats int      `json:"min_verified_stats" jsonschema:"description=Minimum verified statistics to return"`
+	MaxCandidates    int      `json:"max_candidates" jsonschema:"description=Maximum candidates to consider"`
+	ReputableOnly    bool     `json:"reputable_only" jsonschema:"description=Only use reputable sources"`
+	AllowDomains     []string `json:"allow_domains,omitempty" jsonschema:"description=Domains to treat as reputable for this request"`
+	DenyDomains      []string `json:"deny_domains,omitempty" jsonschema:"description=Domains to never use for this request"`
+	Countries        []string `json:"countries,omitempty" jsonschema:"description=ISO country codes to keep (e.g. US)"`
+	PeriodFrom       int      `json:"period_from,omitempty" jsonschema:"description=Keep statistics whose reference period ends in or after this year"`
+	PeriodTo         int      `json:"period_to,omitempty" jsonschema:"description=Keep statistics whose reference period starts in or before this year"`
+	MinRelevance     float64  `json:"min_relevance,omitempty" jsonschema:"description=Drop statistics scoring below this relevance to the topic (0-1)"`
+	PromptVariant    string   `json:"prompt_variant,omitempty" jsonschema:"description=Extraction prompt variant to use (e.g. for A/B comparisons)"`
+	Language         string   `json:"language,omitempty" jsonschema:"description=Search language code (e.g. en or de)"`
+	Country          string   `json:"country,omitempty" jsonschema:"description=Search country code (e.g. US or DE)"`
+	PublishedAfter   string   `json:"published_after,omitempty" jsonschema:"description=Only use sources published on or after this date (YYYY-MM-DD)"`
+	PublishedBefore  string   `json:"published_before,omitempty" jsonschema:"description=Only use sources published before this date (YYYY-MM-DD)"`
+	SiteInclude      []string `json:"site_include,omitempty" jsonschema:"description=Only search these domains"`
+	SiteExclude      []string `json:"site_exclude,omitempty" jsonschema:"description=Never search these domains"`
 }
 
 // NewA2AServer creates a new A2A server for the Eino orchestration agent
-func NewA2AServer(einoAgent *orchestration.EinoOrchestrationAgent, port string) (*A2AServer, error) {
+func NewA2AServer(cfg *config.Config, einoAgent *orchestration.EinoOrchestrationAgent, port string) (*A2AServer, error) {
+	promptRegistry, err := prompts.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	instruction, _, err := promptRegistry.Render(prompts.OrchestrationA2AInstruction, promptRegistry.Preferred(prompts.OrchestrationA2AInstruction, cfg.PromptVariant), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	addr := "0.0.0.0:" + port
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -63,6 +88,21 @@ func NewA2AServer(einoAgent *orchestration.EinoOrchestrationAgent, port string)
 			MinVerifiedStats: input.MinVerifiedStats,
 			MaxCandidates:    input.MaxCandidates,
 			ReputableOnly:    input.ReputableOnly,
+			AllowDomains:     input.AllowDomains,
+			DenyDomains:      input.DenyDomains,
+			Countries:        input.Countries,
+			PeriodFrom:       input.PeriodFrom,
+			PeriodTo:         input.PeriodTo,
+			MinRelevance:     input.MinRelevance,
+			PromptVariant:    input.PromptVariant,
+			SearchOptions: models.SearchOptions{
+				Language:        input.Language,
+				Country:         input.Country,
+				PublishedAfter:  input.PublishedAfter,
+				PublishedBefore: input.PublishedBefore,
+				SiteInclude:     input.SiteInclude,
+				SiteExclude:     input.SiteExclude,
+			},
 		}
 		return einoAgent.Orchestrate(ctx, req)
 	})
@@ -86,12 +126,8 @@ func NewA2AServer(einoAgent *orchestration.EinoOrchestrationAgent, port string)
 		Name:        "eino_orchestration_agent",
 		Model:       model,
 		Description: "Orchestrates multi-agent workflow using Eino graph-based orchestration (deterministic)",
-		Instruction: `You are an orchestration agent that coordinates a statistics research workflow.
-When asked to find statistics on a topic:
-1. Use the orchestrate_statistics_workflow tool with the topic
-2. Return the verified statistics from the response
-The workflow is deterministic (graph-based, not LLM-driven).`,
-		Tools: []tool.Tool{orchestrateTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{orchestrateTool},
 	})
 	if err != nil {
 		listener.Close()
@@ -17,7 +17,7 @@ func main() {
 	// Start A2A server if enabled (standard protocol for agent interoperability)
 	// Note: Eino uses graph-based orchestration, wrapped in ADK for A2A compatibility
 	if cfg.A2AEnabled {
-		a2aServer, err := NewA2AServer(einoAgent, "9000")
+		a2aServer, err := NewA2AServer(cfg, einoAgent, "9000")
 		if err != nil {
 			log.Printf("Failed to create A2A server: %v", err)
 		} else {
@@ -17,21 +17,37 @@ import (
 	"github.com/grokify/stats-agent-team/pkg/httpclient"
 	"github.com/grokify/stats-agent-team/pkg/llm"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+	"github.com/grokify/stats-agent-team/pkg/reputation"
 )
 
 // OrchestrationAgent uses ADK to coordinate research and verification agents
 type OrchestrationAgent struct {
-	cfg      *config.Config
-	client   *http.Client
-	adkAgent agent.Agent
+	cfg        *config.Config
+	client     *http.Client
+	adkAgent   agent.Agent
+	reputation *reputation.Registry
 }
 
 // OrchestrationInput defines input for orchestration tool
 type OrchestrationInput struct {
-	Topic            string `json:"topic"`
-	MinVerifiedStats int    `json:"min_verified_stats"`
-	MaxCandidates    int    `json:"max_candidates"`
-	ReputableOnly    bool   `json:"reputable_only"`
+	Topic            string   `json:"topic"`
+	MinVerifiedStats int      `json:"min_verified_stats"`
+	MaxCandidates    int      `json:"max_candidates"`
+	ReputableOnly    bool     `json:"reputable_only"`
+	AllowDomains     []string `json:"allow_domains,omitempty"`
+	DenyDomains      []string `json:"deny_domains,omitempty"`
+	Countries        []string `json:"countries,omitempty"`
+	PeriodFrom       int      `json:"period_from,omitempty"`
+	PeriodTo         int      `json:"period_to,omitempty"`
+	MinRelevance     float64  `json:"min_relevance,omitempty"`
+	PromptVariant    string   `json:"prompt_variant,omitempty"`
+	Language         string   `json:"language,omitempty"`
+	Country          string   `json:"country,omitempty"`
+	PublishedAfter   string   `json:"published_after,omitempty"`
+	PublishedBefore  string   `json:"published_before,omitempty"`
+	SiteInclude      []string `json:"site_include,omitempty"`
+	SiteExclude      []string `json:"site_exclude,omitempty"`
 }
 
 // OrchestrationToolOutput defines output from orchestration tool
@@ -50,11 +66,26 @@ func NewOrchestrationAgent(cfg *config.Config) (*OrchestrationAgent, error) {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	registry, err := reputation.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reputation registry: %w", err)
+	}
+
+	promptRegistry, err := prompts.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	instruction, _, err := promptRegistry.Render(prompts.OrchestrationInstruction, promptRegistry.Preferred(prompts.OrchestrationInstruction, cfg.PromptVariant), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Printf("Orchestration Agent: Using %s", modelFactory.GetProviderInfo())
 
 	oa := &OrchestrationAgent{
-		cfg:    cfg,
-		client: &http.Client{Timeout: 60 * time.Second},
+		cfg:        cfg,
+		client:     &http.Client{Timeout: 60 * time.Second},
+		reputation: registry,
 	}
 
 	// Create orchestration tool
@@ -71,19 +102,8 @@ func NewOrchestrationAgent(cfg *config.Config) (*OrchestrationAgent, error) {
 		Name:        "statistics_orchestration_agent",
 		Model:       model,
 		Description: "Orchestrates multi-agent workflow to find and verify statistics",
-		Instruction: `You are a statistics orchestration agent. Your job is to:
-1. Coordinate the research agent to find candidate statistics
-2. Send candidates to the verification agent for validation
-3. Retry if needed to meet the target number of verified statistics
-4. Return a final set of verified statistics with sources
-
-Workflow:
-- Request statistics from research agent based on topic
-- Send candidates to verification agent
-- Collect verified statistics
-- If target not met and retries available, request more candidates
-- Build final response with all verified statistics`,
-		Tools: []tool.Tool{orchestrationTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{orchestrationTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ADK agent: %w", err)
@@ -104,6 +124,21 @@ func (oa *OrchestrationAgent) orchestrationToolHandler(ctx tool.Context, input O
 		MinVerifiedStats: input.MinVerifiedStats,
 		MaxCandidates:    input.MaxCandidates,
 		ReputableOnly:    input.ReputableOnly,
+		AllowDomains:     input.AllowDomains,
+		DenyDomains:      input.DenyDomains,
+		Countries:        input.Countries,
+		PeriodFrom:       input.PeriodFrom,
+		PeriodTo:         input.PeriodTo,
+		MinRelevance:     input.MinRelevance,
+		PromptVariant:    input.PromptVariant,
+		SearchOptions: models.SearchOptions{
+			Language:        input.Language,
+			Country:         input.Country,
+			PublishedAfter:  input.PublishedAfter,
+			PublishedBefore: input.PublishedBefore,
+			SiteInclude:     input.SiteInclude,
+			SiteExclude:     input.SiteExclude,
+		},
 	}
 
 	// Use background context since tool.Context is different
@@ -124,6 +159,7 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 	var verifiedStatistics []models.Statistic
 	totalVerified := 0
 	totalFailed := 0
+	totalFiltered := 0
 	maxRetries := 3
 	retry := 0
 
@@ -150,6 +186,9 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 			MinStatistics: candidatesNeeded,
 			MaxStatistics: candidatesNeeded + 5,
 			ReputableOnly: req.ReputableOnly,
+			AllowDomains:  req.AllowDomains,
+			DenyDomains:   req.DenyDomains,
+			SearchOptions: req.SearchOptions,
 		}
 
 		log.Printf("Orchestration: Requesting %d sources from research agent (attempt %d/%d)",
@@ -162,16 +201,7 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 			continue
 		}
 
-		// Convert candidates to search results (research agent returns placeholder candidates now)
-		searchResults := make([]models.SearchResult, 0, len(researchResp.Candidates))
-		for _, cand := range researchResp.Candidates {
-			searchResults = append(searchResults, models.SearchResult{
-				URL:     cand.SourceURL,
-				Title:   cand.Name,
-				Snippet: cand.Excerpt,
-				Domain:  cand.Source,
-			})
-		}
+		searchResults := researchResp.SearchResults
 
 		log.Printf("Orchestration: Received %d sources from research agent", len(searchResults))
 
@@ -181,6 +211,13 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 			SearchResults: searchResults,
 			MinStatistics: candidatesNeeded,
 			MaxStatistics: candidatesNeeded + 5,
+			MinRelevance:  req.MinRelevance,
+			PromptVariant: req.PromptVariant,
+			AllowDomains:  req.AllowDomains,
+			DenyDomains:   req.DenyDomains,
+			// Pages can declare dates the search results lacked
+			PublishedAfter:  req.PublishedAfter,
+			PublishedBefore: req.PublishedBefore,
 		}
 
 		log.Printf("Orchestration: Sending %d sources to synthesis agent", len(searchResults))
@@ -194,10 +231,21 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 
 		log.Printf("Orchestration: Synthesis extracted %d candidates", len(synthesisResp.Candidates))
 		allCandidates = append(allCandidates, synthesisResp.Candidates...)
+		if synthesisResp.RelevanceFiltered > 0 {
+			totalFiltered += synthesisResp.RelevanceFiltered
+			log.Printf("Orchestration: Synthesis dropped %d candidates below relevance %.2f", synthesisResp.RelevanceFiltered, req.MinRelevance)
+		}
+
+		// Drop candidates outside the requested period and geography before verifying
+		candidates := req.FilterCandidates(synthesisResp.Candidates)
+		if filtered := len(synthesisResp.Candidates) - len(candidates); filtered > 0 {
+			totalFiltered += filtered
+			log.Printf("Orchestration: Filtered out %d candidates outside the requested scope", filtered)
+		}
 
 		// Step 3: Send candidates to verification agent
 		verifyReq := &models.VerificationRequest{
-			Candidates: synthesisResp.Candidates,
+			Candidates: candidates,
 		}
 
 		log.Printf("Orchestration: Sending %d candidates to verification agent", len(verifyReq.Candidates))
@@ -216,15 +264,17 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 		for _, result := range verifyResp.Results {
 			if result.Verified {
 				verifiedStatistics = append(verifiedStatistics, *result.Statistic)
-				totalVerified++
 			} else {
 				totalFailed++
 				log.Printf("Statistic failed verification: %s - %s", result.Statistic.Name, result.Reason)
 			}
 		}
 
-		log.Printf("Orchestration: Current progress - %d/%d verified statistics (keeping all verified)",
-			totalVerified, req.MinVerifiedStats)
+		// Count distinct facts: a figure quoted by several sites counts once
+		totalVerified = len(models.ClusterStatistics(verifiedStatistics))
+
+		log.Printf("Orchestration: Current progress - %d/%d verified statistics (keeping all verified, %d sources)",
+			totalVerified, req.MinVerifiedStats, len(verifiedStatistics))
 
 		// Check if we have enough verified statistics to stop gathering more
 		if totalVerified >= req.MinVerifiedStats {
@@ -236,13 +286,20 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 		retry++
 	}
 
+	// Rank statistics so the most reputable sources come first, then merge
+	// statistics of the same fact into the best-sourced one
+	oa.reputation.WithOverrides(req.AllowDomains, req.DenyDomains).RankStatistics(verifiedStatistics)
+	statistics := models.ClusterStatistics(verifiedStatistics)
+
 	// Build final response with ALL verified statistics (not limited to MinVerifiedStats)
 	response := &models.OrchestrationResponse{
 		Topic:           req.Topic,
-		Statistics:      verifiedStatistics, // Returns ALL verified statistics found
+		Statistics:      statistics, // Returns ALL verified statistics found
 		TotalCandidates: len(allCandidates),
 		VerifiedCount:   totalVerified,
 		FailedCount:     totalFailed,
+		FilteredCount:   totalFiltered,
+		MergedCount:     len(verifiedStatistics) - len(statistics),
 		Timestamp:       time.Now(),
 	}
 
@@ -262,7 +319,7 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 // callResearchAgent calls the research agent via HTTP
 func (oa *OrchestrationAgent) callResearchAgent(ctx context.Context, req *models.ResearchRequest) (*models.ResearchResponse, error) {
 	var resp models.ResearchResponse
-	url := fmt.Sprintf("%s/research", oa.cfg.ResearchAgentURL)
+	url := fmt.Sprintf("%s/v2/research", oa.cfg.ResearchAgentURL)
 	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
 		return nil, err
 	}
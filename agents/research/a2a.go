@@ -20,6 +20,8 @@ import (
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 	"google.golang.org/genai"
+
+	"github.com/grokify/stats-agent-team/pkg/models"
 )
 
 // A2AServer represents the A2A protocol server for the Research Agent.
@@ -48,11 +50,23 @@ func NewA2AServer(ra *ResearchAgent, port string) (*A2AServer, error) {
 		Name:        "web_search",
 		Description: "Searches the web for sources related to a topic. Returns URLs and snippets from search results.",
 	}, func(ctx tool.Context, input ResearchInput) (ResearchOutput, error) {
-		results, err := ra.findSources(ctx, input.Topic, input.NumResults, input.ReputableOnly)
+		req := &models.ResearchRequest{
+			Topic:         input.Topic,
+			ReputableOnly: input.ReputableOnly,
+			SearchOptions: models.SearchOptions{
+				Language:        input.Language,
+				Country:         input.Country,
+				PublishedAfter:  input.PublishedAfter,
+				PublishedBefore: input.PublishedBefore,
+				SiteInclude:     input.SiteInclude,
+				SiteExclude:     input.SiteExclude,
+			},
+		}
+		resp, err := ra.findSources(ctx, req, input.NumResults)
 		if err != nil {
 			return ResearchOutput{}, err
 		}
-		return ResearchOutput{SearchResults: results}, nil
+		return ResearchOutput{SearchResults: resp.SearchResults}, nil
 	})
 	if err != nil {
 		listener.Close()
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/grokify/stats-agent-team/pkg/canon"
 	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/llm"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+	"github.com/grokify/stats-agent-team/pkg/reputation"
 	"github.com/grokify/stats-agent-team/pkg/search"
 )
 
@@ -18,16 +21,28 @@ import (
 // Note: This agent now focuses ONLY on search - no LLM analysis
 // Statistics extraction is handled by the Synthesis Agent
 type ResearchAgent struct {
-	cfg       *config.Config
-	client    *http.Client
-	searchSvc *search.Service
+	cfg        *config.Config
+	client     *http.Client
+	searchSvc  *search.Service
+	planner    *search.QueryPlanner
+	reputation *reputation.Registry
 }
 
+// siteFilterCount is how many reputable domains are offered to the planner for site: queries
+const siteFilterCount = 3
+
 // ResearchInput defines the input for the research tool
 type ResearchInput struct {
 	Topic         string `json:"topic" jsonschema:"description=The topic to research statistics for"`
 	NumResults    int    `json:"num_results" jsonschema:"description=Number of search results to return"`
 	ReputableOnly bool   `json:"reputable_only" jsonschema:"description=Only return reputable sources"`
+
+	Language        string   `json:"language,omitempty" jsonschema:"description=Search language code (e.g. en or de)"`
+	Country         string   `json:"country,omitempty" jsonschema:"description=Search country code (e.g. US or DE)"`
+	PublishedAfter  string   `json:"published_after,omitempty" jsonschema:"description=Only sources published on or after this date (YYYY-MM-DD)"`
+	PublishedBefore string   `json:"published_before,omitempty" jsonschema:"description=Only sources published before this date (YYYY-MM-DD)"`
+	SiteInclude     []string `json:"site_include,omitempty" jsonschema:"description=Only search these domains"`
+	SiteExclude     []string `json:"site_exclude,omitempty" jsonschema:"description=Never search these domains"`
 }
 
 // ResearchOutput defines the output from the research tool
@@ -43,72 +58,125 @@ func NewResearchAgent(cfg *config.Config) (*ResearchAgent, error) {
 		return nil, fmt.Errorf("search service required: %w", err)
 	}
 
-	log.Printf("Research Agent: Using %s search provider", cfg.SearchProvider)
+	registry, err := reputation.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reputation registry: %w", err)
+	}
+
+	// LLM query expansion is optional; the agent works without an LLM
+	var expander search.QueryExpander
+	if cfg.SearchQueryLLM {
+		promptRegistry, err := prompts.LoadFromConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prompts: %w", err)
+		}
+		llmModel, err := llm.NewModelFactory(cfg).CreateModel(context.Background())
+		if err != nil {
+			log.Printf("Research Agent: LLM query expansion disabled: %v", err)
+		} else {
+			expander = search.NewLLMQueryExpander(llmModel, promptRegistry, promptRegistry.Preferred(prompts.SearchExpand, cfg.PromptVariant))
+		}
+	}
+
+	log.Printf("Research Agent: Using %s search provider", searchSvc.ProviderName())
+	log.Printf("Research Agent: Up to %d queries per topic, %d pages per query (LLM expansion: %t)", cfg.SearchMaxQueries, cfg.SearchMaxPages, expander != nil)
 	log.Printf("Research Agent: Focuses on finding relevant sources (no LLM analysis)")
 
 	ra := &ResearchAgent{
-		cfg:       cfg,
-		client:    &http.Client{Timeout: 30 * time.Second},
-		searchSvc: searchSvc,
+		cfg:        cfg,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		searchSvc:  searchSvc,
+		planner:    search.NewQueryPlanner(cfg.SearchMaxQueries, registry.SiteFilters(siteFilterCount), expander),
+		reputation: registry,
 	}
 
 	return ra, nil
 }
 
-// findSources performs web search and returns relevant URLs
-func (ra *ResearchAgent) findSources(ctx context.Context, topic string, numResults int, reputableOnly bool) ([]models.SearchResult, error) {
+// findSources performs web search and returns relevant URLs, paging through results
+// until numResults usable sources are found or SEARCH_MAX_PAGES is reached
+func (ra *ResearchAgent) findSources(ctx context.Context, req *models.ResearchRequest, numResults int) (*models.ResearchResponse, error) {
+	topic := req.Topic
 	log.Printf("Research Agent: Searching for sources on topic: %s", topic)
 
+	// Apply per-request allow/deny overrides to the shared registry
+	registry := ra.reputation.WithOverrides(req.AllowDomains, req.DenyDomains)
+
 	if numResults <= 0 {
 		numResults = 10
 	}
 
-	// Perform search
-	searchResp, err := ra.searchSvc.SearchForStatistics(ctx, topic, numResults)
+	after, before, err := req.DateRange()
+	if err != nil {
+		return nil, err
+	}
+	params := search.SearchParams{
+		NumResults:      numResults,
+		Language:        req.Language,
+		Country:         req.Country,
+		PublishedAfter:  after,
+		PublishedBefore: before,
+		SiteInclude:     req.SiteInclude,
+		SiteExclude:     req.SiteExclude,
+	}
+
+	// Plan query variants and run them concurrently
+	queries := ra.planner.Plan(ctx, topic, params)
+	for _, q := range queries {
+		log.Printf("Research Agent: Query (%s): %s", q.Strategy, q.Query)
+	}
+	searchResp, err := ra.searchSvc.SearchQueries(ctx, queries, params, search.PagingOptions{
+		MaxPages: ra.cfg.SearchMaxPages,
+		Counts: func(result search.SearchResult) bool {
+			return !registry.IsBlocked(result.URL) && (!req.ReputableOnly || registry.IsReputable(result.URL))
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	log.Printf("Research Agent: Found %d search results", searchResp.Total)
+	log.Printf("Research Agent: Found %d search results (%d queries, %d pages)", searchResp.Total, len(queries), searchResp.Pages)
 
 	// Convert search results to our model format
 	results := make([]models.SearchResult, 0, len(searchResp.Results))
+	dedup := canon.NewDedup()
 	for i, result := range searchResp.Results {
-		// Filter for reputable sources if requested
-		if reputableOnly && !isReputableSource(result.DisplayLink) {
+		// Blocked sources are always dropped; others only when reputable sources are required
+		if registry.IsBlocked(result.URL) {
+			log.Printf("Filtering out blocked source: %s", result.DisplayLink)
+			continue
+		}
+		if req.ReputableOnly && !registry.IsReputable(result.URL) {
 			log.Printf("Filtering out non-reputable source: %s", result.DisplayLink)
 			continue
 		}
 
+		// Skip URLs already listed under another form (tracking parameters,
+		// AMP variants); the URL the search returned is the one fetched and cited
+		if first, dup := dedup.SeenURL(result.URL); dup {
+			log.Printf("Research Agent: Skipping duplicate source %s (same as %s)", result.URL, first)
+			continue
+		}
+
 		results = append(results, models.SearchResult{
-			URL:      result.URL,
-			Title:    result.Title,
-			Snippet:  result.Snippet,
-			Domain:   result.DisplayLink,
-			Position: i + 1,
+			URL:           result.URL,
+			Title:         result.Title,
+			Snippet:       result.Snippet,
+			Domain:        result.DisplayLink,
+			Position:      i + 1,
+			Provider:      result.Provider,
+			Queries:       result.Queries,
+			PublishedDate: result.PublishedDate,
 		})
 	}
 
 	log.Printf("Research Agent: Returning %d sources", len(results))
-	return results, nil
-}
-
-// isReputableSource checks if a domain is from a reputable source
-func isReputableSource(domain string) bool {
-	reputableDomains := []string{
-		".gov", ".edu", // Government and education
-		"who.int", "un.org", "worldbank.org", // International orgs
-		"pewresearch.org", "gallup.com", // Research organizations
-		"nature.com", "science.org", "nejm.org", // Journals
-	}
-
-	domainLower := strings.ToLower(domain)
-	for _, rep := range reputableDomains {
-		if strings.Contains(domainLower, rep) {
-			return true
-		}
-	}
-	return false
+	return &models.ResearchResponse{
+		Topic:         topic,
+		SearchResults: results,
+		QueriesRun:    len(queries),
+		PagesFetched:  searchResp.Pages,
+	}, nil
 }
 
 // Research finds sources for a given topic (returns URLs, not statistics)
@@ -122,41 +190,54 @@ func (ra *ResearchAgent) Research(ctx context.Context, req *models.ResearchReque
 	}
 
 	// Find sources
-	searchResults, err := ra.findSources(ctx, req.Topic, numResults, req.ReputableOnly)
+	response, err := ra.findSources(ctx, req, numResults)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find sources: %w", err)
 	}
 
-	// Note: We now return SearchResults, which will be analyzed by Synthesis Agent
-	// Convert to old format for backward compatibility (temporary)
-	candidates := make([]models.CandidateStatistic, 0, len(searchResults))
-	for i, result := range searchResults {
-		if i >= req.MaxStatistics && req.MaxStatistics > 0 {
-			break
-		}
-		// Placeholder candidate - real extraction happens in Synthesis Agent
+	// Sources are analyzed by the Synthesis Agent
+	if req.MaxStatistics > 0 && len(response.SearchResults) > req.MaxStatistics {
+		response.SearchResults = response.SearchResults[:req.MaxStatistics]
+	}
+	response.Timestamp = time.Now()
+
+	log.Printf("Research Agent: Found %d sources", len(response.SearchResults))
+	return response, nil
+}
+
+// legacyCandidates converts search results to the placeholder candidates returned
+// by the original /research endpoint, for clients that have not moved to /v2/research
+func legacyCandidates(results []models.SearchResult) []models.CandidateStatistic {
+	candidates := make([]models.CandidateStatistic, 0, len(results))
+	for _, result := range results {
 		candidates = append(candidates, models.CandidateStatistic{
 			Name:      fmt.Sprintf("Source from %s", result.Domain),
-			Value:     0, // Will be extracted by Synthesis Agent
-			Unit:      "",
+			Value:     0, // Extracted by the Synthesis Agent
 			Source:    result.Domain,
 			SourceURL: result.URL,
 			Excerpt:   result.Snippet,
 		})
 	}
+	return candidates
+}
 
-	response := &models.ResearchResponse{
-		Topic:      req.Topic,
-		Candidates: candidates,
-		Timestamp:  time.Now(),
-	}
+// HandleResearchRequest is the HTTP handler for /v2/research, which returns search results
+func (ra *ResearchAgent) HandleResearchRequest(w http.ResponseWriter, r *http.Request) {
+	ra.handleResearch(w, r, false)
+}
 
-	log.Printf("Research Agent: Found %d sources", len(searchResults))
-	return response, nil
+// HandleLegacyResearchRequest is the HTTP handler for /research. It returns the original
+// placeholder-candidate response when RESEARCH_LEGACY_API is enabled.
+func (ra *ResearchAgent) HandleLegacyResearchRequest(w http.ResponseWriter, r *http.Request) {
+	if !ra.cfg.ResearchLegacyAPI {
+		http.Error(w, "The /research endpoint has been replaced by /v2/research (set RESEARCH_LEGACY_API=true to re-enable it)", http.StatusGone)
+		return
+	}
+	ra.handleResearch(w, r, true)
 }
 
-// HandleResearchRequest is the HTTP handler for research requests
-func (ra *ResearchAgent) HandleResearchRequest(w http.ResponseWriter, r *http.Request) {
+// handleResearch decodes a research request, runs it and writes the response
+func (ra *ResearchAgent) handleResearch(w http.ResponseWriter, r *http.Request, legacy bool) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -175,12 +256,20 @@ func (ra *ResearchAgent) HandleResearchRequest(w http.ResponseWriter, r *http.Re
 	if req.MaxStatistics == 0 {
 		req.MaxStatistics = 30 // Increased from 10 to match ChatGPT.com performance
 	}
+	if _, _, err := req.DateRange(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	resp, err := ra.Research(r.Context(), &req)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Research failed: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if legacy {
+		resp.Candidates = legacyCandidates(resp.SearchResults)
+		resp.SearchResults = nil
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -220,7 +309,8 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	http.HandleFunc("/research", researchAgent.HandleResearchRequest)
+	http.HandleFunc("/v2/research", researchAgent.HandleResearchRequest)
+	http.HandleFunc("/research", researchAgent.HandleLegacyResearchRequest)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+
+	agentbase "github.com/grokify/stats-agent-team/pkg/agent"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/numscan"
+)
+
+// Scan modes (SYNTHESIS_SCAN_MODE) for LLM candidates the number scanner can't confirm
+const (
+	scanModeDiscard = "discard" // Drop the candidate
+	scanModeFlag    = "flag"    // Keep it, with the reason in ScanMismatch
+	scanModeOff     = "off"     // Don't cross-check
+)
+
+// crossCheck confirms LLM candidates against the page text with the rule-based
+// number scanner: the excerpt must appear in the text and the scanner must find
+// the value (both ends of a range) inside it, as a percentage if the unit is one. Unconfirmed candidates are
+// discarded or flagged depending on the scan mode, and returned as mismatches.
+func (sa *SynthesisAgent) crossCheck(page *agentbase.Page, candidates []models.CandidateStatistic) ([]models.CandidateStatistic, []models.ScanMismatch) {
+	mode := sa.Cfg.SynthesisScanMode
+	if mode == scanModeOff || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	index := numscan.NewIndex(page.Text)
+	kept := candidates[:0]
+	var mismatches []models.ScanMismatch
+	for _, c := range candidates {
+		values := []float64{c.Value}
+		if c.ValueKind == models.ValueKindRange && c.ValueMin != nil && c.ValueMax != nil {
+			values = []float64{*c.ValueMin, *c.ValueMax}
+		}
+		err := index.Confirm(c.Excerpt, numscan.UnitKind(c.Unit), values...)
+		if err == nil {
+			kept = append(kept, c)
+			continue
+		}
+
+		discard := mode != scanModeFlag
+		log.Printf("Synthesis Agent: Unconfirmed statistic %q from %s: %v", c.Name, c.SourceURL, err)
+		mismatches = append(mismatches, models.ScanMismatch{Candidate: c, Reason: err.Error(), Discarded: discard})
+		if !discard {
+			c.ScanMismatch = err.Error()
+			kept = append(kept, c)
+		}
+	}
+	return kept, mismatches
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/model"
+
+	"github.com/grokify/stats-agent-team/pkg/llm"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+// excerptAgreement is the share of the shorter excerpt's words the other must
+// contain for two models' extractions of a value to count as the same statistic
+const excerptAgreement = 0.5
+
+// ensembleMember is one model of the extraction ensemble
+type ensembleMember struct {
+	name    string // provider:model as configured
+	model   model.LLM
+	factory *llm.ModelFactory
+}
+
+// newEnsemble creates the models listed in SYNTHESIS_ENSEMBLE
+// ("provider:model,provider:model"; the model is optional) and resolves the
+// agreement level (SYNTHESIS_ENSEMBLE_AGREEMENT, 0 = majority). It returns no
+// members if the ensemble is off.
+func newEnsemble(ctx context.Context, factory *llm.ModelFactory, spec string, agreement int) ([]ensembleMember, int, error) {
+	var members []ensembleMember
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// Split at the first colon: Ollama model names have tags ("ollama:llama3:latest")
+		provider, modelName, _ := strings.Cut(entry, ":")
+		f := factory.ForModel(strings.TrimSpace(provider), strings.TrimSpace(modelName))
+		m, err := f.CreateModel(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create ensemble model %s: %w", entry, err)
+		}
+		members = append(members, ensembleMember{name: entry, model: m, factory: f})
+	}
+	switch {
+	case len(members) == 0:
+		return nil, 0, nil
+	case len(members) == 1:
+		return nil, 0, fmt.Errorf("extraction ensemble needs at least two models, got %s", spec)
+	case agreement <= 0:
+		agreement = len(members)/2 + 1
+	case agreement > len(members):
+		return nil, 0, fmt.Errorf("ensemble agreement %d exceeds the %d ensemble models", agreement, len(members))
+	}
+	return members, agreement, nil
+}
+
+// extractWithEnsemble runs an extraction prompt on every ensemble model
+// concurrently and keeps the statistics at least the agreement level of models
+// extracted (see vote). Models that fail don't vote; if fewer than the
+// agreement level respond, no statistic could be kept and the chunk fails
+// rather than silently yielding nothing.
+func (sa *SynthesisAgent) extractWithEnsemble(ctx context.Context, prompt string, used *prompts.Prompt, result models.SearchResult) ([]models.CandidateStatistic, error) {
+	outputs := make([][]models.CandidateStatistic, len(sa.ensemble))
+	errs := make([]error, len(sa.ensemble))
+	var wg sync.WaitGroup
+	for i, member := range sa.ensemble {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outputs[i], errs[i] = sa.extractWithModel(ctx, member.model, prompt, used, result)
+		}()
+	}
+	wg.Wait()
+
+	names := make([]string, len(sa.ensemble))
+	var failures []error
+	for i, member := range sa.ensemble {
+		names[i] = member.name
+		if errs[i] != nil {
+			log.Printf("Synthesis Agent: Ensemble model %s failed on %s: %v", member.name, result.URL, errs[i])
+			failures = append(failures, fmt.Errorf("%s: %w", member.name, errs[i]))
+		}
+	}
+	if responded := len(sa.ensemble) - len(failures); responded < sa.agreement {
+		return nil, fmt.Errorf("only %d of %d ensemble models responded, %d must agree: %w",
+			responded, len(sa.ensemble), sa.agreement, errors.Join(failures...))
+	}
+
+	candidates, rejected := vote(names, outputs, sa.agreement)
+	if rejected > 0 {
+		log.Printf("Synthesis Agent: Dropped %d statistics from %s extracted by fewer than %d of %d models",
+			rejected, result.Domain, sa.agreement, len(sa.ensemble))
+	}
+	return candidates, nil
+}
+
+// vote aligns the statistics each model extracted (outputs, in the order of
+// names) and keeps those extracted by at least agreement models, annotated with
+// the models that agreed. Two extractions align when their values are equal and
+// their excerpts quote the same passage (see sameExtraction). Each kept
+// statistic is the version of the first model in the ensemble that extracted
+// it. Returns the kept statistics and how many were rejected.
+func vote(names []string, outputs [][]models.CandidateStatistic, agreement int) ([]models.CandidateStatistic, int) {
+	type group struct {
+		candidate models.CandidateStatistic
+		voters    map[int]bool
+	}
+	var groups []*group
+	for i, output := range outputs {
+		for _, c := range output {
+			var match *group
+			repeat := false
+			for _, g := range groups {
+				if !sameExtraction(g.candidate, c) {
+					continue
+				}
+				if g.voters[i] {
+					repeat = true // The model listed the statistic twice
+					continue
+				}
+				match = g
+				break
+			}
+			switch {
+			case match != nil:
+				match.voters[i] = true
+			case !repeat:
+				groups = append(groups, &group{candidate: c, voters: map[int]bool{i: true}})
+			}
+		}
+	}
+
+	var kept []models.CandidateStatistic
+	rejected := 0
+	for _, g := range groups {
+		if len(g.voters) < agreement {
+			rejected++
+			continue
+		}
+		c := g.candidate
+		c.AgreedModels = nil
+		for i, name := range names {
+			if g.voters[i] {
+				c.AgreedModels = append(c.AgreedModels, name)
+			}
+		}
+		kept = append(kept, c)
+	}
+	return kept, rejected
+}
+
+// sameExtraction reports whether two models extracted the same statistic: equal
+// values (and range ends) with excerpts that contain one another or share most
+// of their words
+func sameExtraction(a, b models.CandidateStatistic) bool {
+	if !models.ValuesEqual(a.Value, b.Value) {
+		return false
+	}
+	if a.ValueKind == models.ValueKindRange && b.ValueKind == models.ValueKindRange &&
+		a.ValueMax != nil && b.ValueMax != nil && !models.ValuesEqual(*a.ValueMax, *b.ValueMax) {
+		return false
+	}
+	ea, eb := normalizeExcerpt(a.Excerpt), normalizeExcerpt(b.Excerpt)
+	if strings.Contains(ea, eb) || strings.Contains(eb, ea) {
+		return true
+	}
+	wa, wb := strings.Fields(ea), strings.Fields(eb)
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	if len(wa) == 0 {
+		return false
+	}
+	inB := make(map[string]bool, len(wb))
+	for _, w := range wb {
+		inB[w] = true
+	}
+	shared := 0
+	for _, w := range wa {
+		if inB[w] {
+			shared++
+		}
+	}
+	return float64(shared)/float64(len(wa)) >= excerptAgreement
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	agentbase "github.com/grokify/stats-agent-team/pkg/agent"
+	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+func stat(name string, value float64, excerpt string) models.CandidateStatistic {
+	return models.CandidateStatistic{Name: name, Value: value, Excerpt: excerpt}
+}
+
+func TestVote(t *testing.T) {
+	names := []string{"gemini", "claude", "ollama"}
+	sales := stat("EV sales", 14e6, "Electric car sales neared 14 million in 2023")
+	salesReworded := stat("Global EV sales", 14e6, "electric car sales neared 14 million")
+	share := stat("EV share", 18, "18% of all cars sold were electric")
+	otherShare := stat("EV share", 18, "Norway's share of EVs in 2015 was 18 percent of registrations")
+	tests := []struct {
+		name         string
+		outputs      [][]models.CandidateStatistic
+		agreement    int
+		wantKept     []string // Names of kept statistics and their agreed models
+		wantRejected int
+	}{
+		{"unanimous", [][]models.CandidateStatistic{{sales}, {salesReworded}, {sales}}, 3,
+			[]string{"EV sales: gemini,claude,ollama"}, 0},
+		{"majority", [][]models.CandidateStatistic{{sales, share}, {salesReworded}, {sales}}, 2,
+			[]string{"EV sales: gemini,claude,ollama"}, 1},
+		{"first extractor's version", [][]models.CandidateStatistic{nil, {salesReworded}, {sales}}, 2,
+			[]string{"Global EV sales: claude,ollama"}, 0},
+		{"same value, different passage", [][]models.CandidateStatistic{{share}, {otherShare}, nil}, 2,
+			nil, 2},
+		{"repeat within one model", [][]models.CandidateStatistic{{sales, sales}, nil, nil}, 2,
+			nil, 1},
+		{"nothing extracted", [][]models.CandidateStatistic{nil, nil, nil}, 1, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, rejected := vote(names, tt.outputs, tt.agreement)
+			var got []string
+			for _, c := range kept {
+				got = append(got, c.Name+": "+strings.Join(c.AgreedModels, ","))
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.wantKept, "; ") {
+				t.Errorf("kept %q, want %q", got, tt.wantKept)
+			}
+			if rejected != tt.wantRejected {
+				t.Errorf("rejected %d, want %d", rejected, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestSameExtraction(t *testing.T) {
+	lo, hi, otherHi := 10.0, 20.0, 25.0
+	rangeStat := func(max *float64) models.CandidateStatistic {
+		return models.CandidateStatistic{Value: lo, ValueKind: models.ValueKindRange, ValueMin: &lo, ValueMax: max, Excerpt: "between 10 and 20 percent"}
+	}
+	tests := []struct {
+		name string
+		a, b models.CandidateStatistic
+		want bool
+	}{
+		{"contained excerpt", stat("a", 5, "Sales rose 5% in 2023."), stat("b", 5, "sales rose 5%"), true},
+		{"shared words", stat("a", 5, "sales in Europe rose 5% last year"), stat("b", 5, "European sales rose 5% last year overall"), true},
+		{"different value", stat("a", 5, "sales rose 5%"), stat("b", 6, "sales rose 5%"), false},
+		{"different passage", stat("a", 5, "sales rose 5%"), stat("b", 5, "prices fell by 5 dollars"), false},
+		{"same range", rangeStat(&hi), rangeStat(&hi), true},
+		{"different range end", rangeStat(&hi), rangeStat(&otherHi), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameExtraction(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameExtraction = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ensembleModel answers every extraction with a fixed response or error
+type ensembleModel struct {
+	response string
+	err      error
+}
+
+func (m *ensembleModel) Name() string { return "ensemble" }
+
+func (m *ensembleModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if m.err != nil {
+			yield(nil, m.err)
+			return
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.response, genai.RoleModel)}, nil)
+	}
+}
+
+func TestExtractWithEnsemble(t *testing.T) {
+	const sales = `[{"name": "EV sales", "value": 14000000, "value_text": "14 million", "excerpt": "sales neared 14 million"}]`
+	ok := &ensembleModel{response: sales}
+	down := &ensembleModel{err: errors.New("503 Service Unavailable")}
+	tests := []struct {
+		name      string
+		models    []*ensembleModel
+		agreement int
+		wantStats int
+		wantErr   string
+	}{
+		{"all respond", []*ensembleModel{ok, ok, ok}, 2, 1, ""},
+		{"enough respond", []*ensembleModel{ok, down, ok}, 2, 1, ""},
+		{"too few respond", []*ensembleModel{ok, down, down}, 2, 0, "only 1 of 3 ensemble models responded, 2 must agree"},
+		{"none respond", []*ensembleModel{down, down}, 1, 0, "503 Service Unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := &SynthesisAgent{
+				BaseAgent: &agentbase.BaseAgent{Cfg: &config.Config{}},
+				prompts:   prompts.Default(),
+				agreement: tt.agreement,
+			}
+			for i, m := range tt.models {
+				sa.ensemble = append(sa.ensemble, ensembleMember{name: string(rune('a' + i)), model: m})
+			}
+			used, _ := sa.prompts.Get(prompts.SynthesisExtract, "")
+			stats, err := sa.extractWithEnsemble(context.Background(), "Extract.", used, models.SearchResult{URL: "https://example.org", Domain: "example.org"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractWithEnsemble: %v", err)
+			}
+			if len(stats) != tt.wantStats {
+				t.Errorf("got %d statistics, want %d", len(stats), tt.wantStats)
+			}
+		})
+	}
+}
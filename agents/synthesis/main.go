@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/adk/agent"
@@ -14,25 +15,42 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
-	"google.golang.org/genai"
 
 	agentbase "github.com/grokify/stats-agent-team/pkg/agent"
+	"github.com/grokify/stats-agent-team/pkg/canon"
+	"github.com/grokify/stats-agent-team/pkg/chunk"
 	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/llm"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+	"github.com/grokify/stats-agent-team/pkg/relevance"
+	"github.com/grokify/stats-agent-team/pkg/reputation"
 )
 
 // SynthesisAgent extracts statistics from webpage content using LLM
 type SynthesisAgent struct {
 	*agentbase.BaseAgent
-	adkAgent agent.Agent
+	adkAgent   agent.Agent
+	reputation *reputation.Registry
+	relevance  relevance.Scorer // nil if relevance scoring is off
+	prompts    *prompts.Registry
+	ensemble   []ensembleMember // Models extracting each text chunk; empty if the ensemble is off. Tables and relevance use Model alone
+	agreement  int              // Ensemble models that must agree on a statistic
 }
 
-// SynthesisInput defines input for synthesis tool
+// SynthesisInput defines input for synthesis tool. The optional fields are
+// those of models.SynthesisRequest.
 type SynthesisInput struct {
-	Topic         string                `json:"topic"`
-	SearchResults []models.SearchResult `json:"search_results"`
-	MinStatistics int                   `json:"min_statistics"`
-	MaxStatistics int                   `json:"max_statistics"`
+	Topic           string                `json:"topic"`
+	SearchResults   []models.SearchResult `json:"search_results"`
+	MinStatistics   int                   `json:"min_statistics"`
+	MaxStatistics   int                   `json:"max_statistics"`
+	MinRelevance    float64               `json:"min_relevance,omitempty"`
+	PromptVariant   string                `json:"prompt_variant,omitempty"`
+	AllowDomains    []string              `json:"allow_domains,omitempty"`
+	DenyDomains     []string              `json:"deny_domains,omitempty"`
+	PublishedAfter  string                `json:"published_after,omitempty"`
+	PublishedBefore string                `json:"published_before,omitempty"`
 }
 
 // SynthesisToolOutput defines output from synthesis tool
@@ -48,10 +66,44 @@ func NewSynthesisAgent(cfg *config.Config) (*SynthesisAgent, error) {
 		return nil, fmt.Errorf("failed to create base agent: %w", err)
 	}
 
+	registry, err := reputation.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reputation registry: %w", err)
+	}
+
+	promptRegistry, err := prompts.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	instruction, _, err := promptRegistry.Render(prompts.SynthesisInstruction,
+		promptRegistry.Preferred(prompts.SynthesisInstruction, cfg.PromptVariant), struct{ ReputableSources string }{registry.Describe()})
+	if err != nil {
+		return nil, err
+	}
+
+	scorer, err := relevance.NewFromConfig(cfg, base.Model, base.Client, promptRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relevance scorer: %w", err)
+	}
+
+	ensemble, agreement, err := newEnsemble(context.Background(), base.ModelFactory, cfg.SynthesisEnsemble, cfg.SynthesisEnsembleAgreement)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Printf("Synthesis Agent: Using %s", base.GetProviderInfo())
+	if len(ensemble) > 0 {
+		log.Printf("Synthesis Agent: Extracting text with %d models (%s), keeping statistics %d agree on; tables and relevance use %s",
+			len(ensemble), cfg.SynthesisEnsemble, agreement, base.Model.Name())
+	}
 
 	sa := &SynthesisAgent{
-		BaseAgent: base,
+		BaseAgent:  base,
+		reputation: registry,
+		relevance:  scorer,
+		prompts:    promptRegistry,
+		ensemble:   ensemble,
+		agreement:  agreement,
 	}
 
 	// Create synthesis tool
@@ -68,27 +120,8 @@ func NewSynthesisAgent(cfg *config.Config) (*SynthesisAgent, error) {
 		Name:        "statistics_synthesis_agent",
 		Model:       base.Model,
 		Description: "Extracts statistics from web content",
-		Instruction: `You are a statistics synthesis agent. Your job is to:
-1. Fetch content from provided URLs
-2. Analyze the content to find numerical statistics
-3. Extract exact values, units, and context
-4. Create verbatim excerpts containing the statistics
-5. Identify the source credibility
-
-When extracting statistics:
-- Look for numerical values with context (percentages, measurements, counts)
-- Extract the exact excerpt containing the statistic (word-for-word)
-- Identify the unit of measurement
-- Verify the source is reputable (academic, government, research)
-- Only extract statistics that are clearly stated with numbers
-
-Reputable sources include:
-- Government agencies (.gov domains)
-- Academic institutions (.edu domains)
-- Research organizations (Pew, Gallup, etc.)
-- International organizations (WHO, UN, World Bank, etc.)
-- Peer-reviewed journals`,
-		Tools: []tool.Tool{synthesisTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{synthesisTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ADK agent: %w", err)
@@ -99,211 +132,418 @@ Reputable sources include:
 	return sa, nil
 }
 
-// synthesisToolHandler implements the synthesis logic
+// synthesisToolHandler runs the tool input through Synthesize, so tool calls
+// get the same reputation, date and relevance filtering as HTTP requests
 func (sa *SynthesisAgent) synthesisToolHandler(ctx tool.Context, input SynthesisInput) (SynthesisToolOutput, error) {
-	log.Printf("Synthesis Agent: Analyzing %d URLs for topic: %s", len(input.SearchResults), input.Topic)
+	req := &models.SynthesisRequest{
+		Topic:           input.Topic,
+		SearchResults:   input.SearchResults,
+		MinStatistics:   input.MinStatistics,
+		MaxStatistics:   input.MaxStatistics,
+		MinRelevance:    input.MinRelevance,
+		PromptVariant:   input.PromptVariant,
+		AllowDomains:    input.AllowDomains,
+		DenyDomains:     input.DenyDomains,
+		PublishedAfter:  input.PublishedAfter,
+		PublishedBefore: input.PublishedBefore,
+	}
+	if err := sa.prepareRequest(req); err != nil {
+		return SynthesisToolOutput{}, err
+	}
+	resp, err := sa.Synthesize(ctx, req)
+	if err != nil {
+		return SynthesisToolOutput{}, err
+	}
+	return SynthesisToolOutput{Candidates: resp.Candidates}, nil
+}
 
-	candidates := make([]models.CandidateStatistic, 0)
+// Chunk sizing. Token counts are estimated at ~4 characters per token.
+const (
+	charsPerToken     = 4
+	promptTokens      = 1500  // Extraction instructions and examples
+	maxResponseTokens = 4096  // Room reserved for the JSON answer
+	minChunkChars     = 2000  // Smaller chunks lose too much context
+	maxChunkChars     = 30000 // Larger chunks get skimmed: fewer statistics per character
+	minOverlapChars   = 300
+	maxOverlapChars   = 1500
+)
 
-	// Analyze each search result
-	for i, result := range input.SearchResults {
-		if len(candidates) >= input.MaxStatistics && input.MaxStatistics > 0 {
-			break
+// chunkSize returns the chunk size and overlap in characters for the configured
+// model's context window, or the smallest window of the ensemble models
+func (sa *SynthesisAgent) chunkSize() (size, overlap int) {
+	window := sa.ModelFactory.ContextWindow()
+	if len(sa.ensemble) > 0 {
+		window = sa.ensemble[0].factory.ContextWindow()
+		for _, member := range sa.ensemble[1:] {
+			window = min(window, member.factory.ContextWindow())
 		}
+	}
+	tokens := window - promptTokens - min(window/4, maxResponseTokens)
+	size = max(minChunkChars, min(tokens*charsPerToken, maxChunkChars))
+	overlap = max(minOverlapChars, min(size/10, maxOverlapChars))
+	return size, overlap
+}
 
-		log.Printf("Synthesis Agent: Fetching content from %s", result.URL)
+// extractStatisticsWithLLM extracts statistics from a page's text and HTML
+// tables. Long texts are split into overlapping chunks that fit the model's
+// context window and extracted concurrently; statistics found twice where chunks
+// overlap are merged. Statistics from the text are cross-checked against the
+// numbers on the page (see crossCheck); those that fail are returned as
+// mismatches. Prompts are rendered in the given variant (see prompts.Registry).
+// It fails only if nothing could be extracted.
+func (sa *SynthesisAgent) extractStatisticsWithLLM(ctx context.Context, topic, variant string, result models.SearchResult, page *agentbase.Page) ([]models.CandidateStatistic, []models.ScanMismatch, error) {
+	tableStats, err := sa.extractTableStatistics(ctx, topic, variant, result, page)
+	if err != nil {
+		log.Printf("Synthesis Agent: Table extraction failed for %s: %v", result.URL, err)
+	}
 
-		// Fetch webpage content using base agent method
-		content, err := sa.FetchURL(context.Background(), result.URL, 1)
-		if err != nil {
-			log.Printf("Failed to fetch %s: %v", result.URL, err)
-			continue
+	candidates, err := sa.extractTextStatistics(ctx, topic, variant, result, page.Text)
+	if err != nil {
+		if len(tableStats) == 0 {
+			return nil, nil, err
+		}
+		log.Printf("Synthesis Agent: Text extraction failed for %s: %v", result.URL, err)
+	}
+	// Record the page of PDF statistics for verification and citations
+	for i := range candidates {
+		candidates[i].Page = page.PageOf(candidates[i].Excerpt)
+	}
+	candidates, mismatches := sa.crossCheck(page, candidates)
+	candidates = append(dropTableDuplicates(candidates, tableStats), tableStats...)
+	setSourceMetadata(candidates, page, result)
+	return candidates, mismatches, nil
+}
+
+// setSourceMetadata cites the publisher, authors and dates a page declares on
+// its candidates, naming the source after the publisher rather than the domain
+// when known. Pages without a publication date fall back to the date reported
+// by the search provider.
+func setSourceMetadata(candidates []models.CandidateStatistic, page *agentbase.Page, result models.SearchResult) {
+	meta := page.Meta
+	if meta.Published == "" {
+		meta.Published = result.PublishedDate
+	}
+	for i := range candidates {
+		c := &candidates[i]
+		if meta.Publisher != "" {
+			c.Source = meta.Publisher
 		}
+		c.Publisher = meta.Publisher
+		c.Author = meta.Author
+		c.PublishedDate = meta.Published
+		c.ModifiedDate = meta.Modified
+	}
+}
+
+// extractTextStatistics extracts statistics from text, chunk by chunk
+func (sa *SynthesisAgent) extractTextStatistics(ctx context.Context, topic, variant string, result models.SearchResult, text string) ([]models.CandidateStatistic, error) {
+	size, overlap := sa.chunkSize()
+	chunks := chunk.Split(text, size, overlap)
+	if maxChunks := sa.Cfg.SynthesisMaxChunks; maxChunks > 0 && len(chunks) > maxChunks {
+		log.Printf("Synthesis Agent: %s has %d chunks, extracting the first %d", result.URL, len(chunks), maxChunks)
+		chunks = chunks[:maxChunks]
+	}
+	if len(chunks) == 1 {
+		return sa.extractChunkStatistics(ctx, topic, variant, result, chunks[0].Text, 1, 1)
+	}
+	log.Printf("Synthesis Agent: Extracting %s in %d chunks of up to %d characters", result.URL, len(chunks), size)
+
+	stats := make([][]models.CandidateStatistic, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, max(1, sa.Cfg.SynthesisChunkConcurrency))
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			stats[i], errs[i] = sa.extractChunkStatistics(ctx, topic, variant, result, c.Text, i+1, len(chunks))
+			for j := range stats[i] {
+				stats[i][j].ChunkOffset = c.Offset
+			}
+		}()
+	}
+	wg.Wait()
 
-		// Extract statistics from content using LLM
-		stats, err := sa.extractStatisticsWithLLM(context.Background(), input.Topic, result, content)
+	var candidates []models.CandidateStatistic
+	failed := 0
+	for i, err := range errs {
 		if err != nil {
-			log.Printf("Failed to extract statistics from %s: %v", result.URL, err)
+			log.Printf("Synthesis Agent: Chunk %d/%d of %s failed: %v", i+1, len(chunks), result.URL, err)
+			failed++
 			continue
 		}
-		candidates = append(candidates, stats...)
-
-		log.Printf("Synthesis Agent: Extracted %d statistics from %s (total: %d/%d)",
-			len(stats), result.Domain, len(candidates), input.MinStatistics)
+		candidates = mergeChunkCandidates(candidates, stats[i])
+	}
+	if failed == len(chunks) {
+		return nil, fmt.Errorf("all %d chunks failed: %w", len(chunks), errs[0])
+	}
+	return candidates, nil
+}
 
-		// Stop if we have enough
-		if len(candidates) >= input.MinStatistics && i > 2 {
-			break
+// mergeChunkCandidates appends a chunk's candidates to those of earlier chunks,
+// skipping statistics already extracted from the overlap with a previous chunk:
+// the same value with one excerpt containing the other. The longer excerpt is kept.
+func mergeChunkCandidates(merged, next []models.CandidateStatistic) []models.CandidateStatistic {
+	for _, c := range next {
+		dup := false
+		for i, prev := range merged {
+			if !models.ValuesEqual(prev.Value, c.Value) {
+				continue
+			}
+			a, b := normalizeExcerpt(prev.Excerpt), normalizeExcerpt(c.Excerpt)
+			if strings.Contains(a, b) {
+				dup = true
+			} else if strings.Contains(b, a) {
+				merged[i] = c
+				dup = true
+			}
+			if dup {
+				break
+			}
+		}
+		if !dup {
+			merged = append(merged, c)
 		}
 	}
+	return merged
+}
 
-	log.Printf("Synthesis Agent: Total candidates extracted: %d", len(candidates))
-
-	return SynthesisToolOutput{
-		Candidates: candidates,
-	}, nil
+// normalizeExcerpt lowercases an excerpt and collapses its whitespace
+func normalizeExcerpt(excerpt string) string {
+	return strings.Join(strings.Fields(strings.ToLower(excerpt)), " ")
 }
 
-// extractStatisticsWithLLM uses LLM to intelligently extract statistics from content
-func (sa *SynthesisAgent) extractStatisticsWithLLM(ctx context.Context, topic string, result models.SearchResult, content string) ([]models.CandidateStatistic, error) {
-	// Truncate content if too long (LLMs have token limits)
-	maxContentLen := 30000 // ~8000 tokens - increased from 15000 to capture more statistics
-	if len(content) > maxContentLen {
-		content = content[:maxContentLen]
+// extractChunkStatistics uses LLM to intelligently extract statistics from one
+// chunk (part of parts) of a page's main text
+func (sa *SynthesisAgent) extractChunkStatistics(ctx context.Context, topic, variant string, result models.SearchResult, content string, part, parts int) ([]models.CandidateStatistic, error) {
+	// Create prompt for LLM to extract statistics
+	prompt, used, err := sa.prompts.Render(prompts.SynthesisExtract, variant, struct {
+		Topic, URL, Domain, Content string
+		Part, Parts                 int
+	}{topic, result.URL, result.Domain, content, part, parts})
+	if err != nil {
+		return nil, err
 	}
 
-	// Create prompt for LLM to extract statistics
-	prompt := fmt.Sprintf(`Analyze the following webpage content and extract ALL numerical statistics related to "%s".
-
-IMPORTANT RULES:
-1. Extract EVERY statistic you find, not just one or two. Be thorough and comprehensive.
-2. The "value" field MUST be the exact number that appears in the excerpt - do not approximate or round
-3. The "excerpt" MUST be a verbatim quote containing the exact number you put in "value"
-4. If the excerpt says "1.5°C", the value must be 1.5, not 1
-5. If you cannot find an exact number in the text, skip that statistic
-
-For each statistic found, provide:
-1. name: A brief descriptive name
-2. value: The EXACT numerical value from the text (as a number, not string)
-3. unit: The unit of measurement (percent, million, billion, degrees Celsius, people, countries, etc.)
-4. excerpt: The verbatim excerpt from the text containing this EXACT statistic (50-200 characters)
-
-Return valid JSON array with this structure:
-[
-  {
-    "name": "Global temperature rise",
-    "value": 1.5,
-    "unit": "degrees Celsius",
-    "excerpt": "limiting global warming to 1.5°C above pre-industrial levels"
-  },
-  {
-    "name": "Survey respondents",
-    "value": 75000,
-    "unit": "people",
-    "excerpt": "Over 75,000 people across 77 countries participated"
-  }
-]
-
-CRITICAL: The value field must match the number in the excerpt exactly. Do not invent numbers.
-
-Extract ALL statistics with clear numerical values. If the page contains 10 statistics, return 10 items in the array.
-Return empty array [] ONLY if absolutely no statistics are found.
-
-Webpage URL: %s
-Domain: %s
-
-Content:
-%s
-
-JSON output with ALL statistics:`, topic, result.URL, result.Domain, content)
-
-	// Call LLM to extract statistics using ADK
-	llmReq := &model.LLMRequest{
-		Contents: genai.Text(prompt),
-	}
-
-	var response string
-	for llmResp, err := range sa.Model.GenerateContent(ctx, llmReq, false) {
-		if err != nil {
-			return nil, fmt.Errorf("LLM generation failed: %w", err)
-		}
-		// Extract text from response
-		if llmResp.Content != nil && llmResp.Content.Parts != nil {
-			for _, part := range llmResp.Content.Parts {
-				if part.Text != "" {
-					response += part.Text
-				}
-			}
-		}
+	if len(sa.ensemble) > 0 {
+		return sa.extractWithEnsemble(ctx, prompt, used, result)
 	}
+	return sa.extractWithModel(ctx, sa.Model, prompt, used, result)
+}
 
-	// Parse JSON response
+// extractWithModel runs an extraction prompt on a model and converts the
+// statistics it returns into candidates
+func (sa *SynthesisAgent) extractWithModel(ctx context.Context, m model.LLM, prompt string, used *prompts.Prompt, result models.SearchResult) ([]models.CandidateStatistic, error) {
+	// The response schema: name, value and excerpt are required
 	type StatExtraction struct {
-		Name    string  `json:"name"`
-		Value   float32 `json:"value"`
-		Unit    string  `json:"unit"`
-		Excerpt string  `json:"excerpt"`
-	}
-
-	var extractions []StatExtraction
-	if err := json.Unmarshal([]byte(response), &extractions); err != nil {
-		// LLM might wrap JSON in markdown code blocks
-		response = extractJSONFromMarkdown(response)
-		if err := json.Unmarshal([]byte(response), &extractions); err != nil {
-			return nil, fmt.Errorf("failed to parse LLM response as JSON: %w (response: %s)", err, response)
-		}
+		Name        string              `json:"name"`
+		Value       float64             `json:"value"`
+		ValueText   string              `json:"value_text,omitempty"`
+		ValueKind   string              `json:"value_kind,omitempty"`
+		ValueMin    *float64            `json:"value_min,omitempty"`
+		ValueMax    *float64            `json:"value_max,omitempty"`
+		Unit        string              `json:"unit,omitempty"`
+		Excerpt     string              `json:"excerpt"`
+		Period      *models.Period      `json:"period,omitempty"`
+		Geography   *models.Geography   `json:"geography,omitempty"`
+		Population  string              `json:"population,omitempty"`
+		Methodology *models.Methodology `json:"methodology,omitempty"`
+	}
+
+	// Call LLM to extract statistics, re-prompting if the JSON doesn't match the schema
+	extractions, err := llm.GenerateJSON[[]StatExtraction](ctx, m, prompt, llm.JSONOptions{MaxRepairs: sa.Cfg.LLMMaxRepairs, Prompts: sa.prompts})
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert to CandidateStatistic
 	candidates := make([]models.CandidateStatistic, 0, len(extractions))
 	for _, ext := range extractions {
-		if ext.Value == 0 || ext.Excerpt == "" {
-			continue // Skip invalid entries
+		kind := models.ParseValueKind(ext.ValueKind)
+		if kind == models.ValueKindRange {
+			// A range needs both ends; otherwise fall back to a point value
+			if ext.ValueMin == nil || ext.ValueMax == nil {
+				kind = models.ValueKindPoint
+				ext.ValueMin, ext.ValueMax = nil, nil
+			} else if ext.Value == 0 {
+				ext.Value = *ext.ValueMin
+			}
+		} else {
+			ext.ValueMin, ext.ValueMax = nil, nil
+		}
+
+		// Skip extractions missing their quote or value. Zero is a real value
+		// ("0% of respondents"), so the value counts as missing only when it has
+		// no literal text and isn't written in the excerpt.
+		if ext.Excerpt == "" || (ext.ValueText == "" && !models.ValueInText(ext.Value, ext.Excerpt, "")) {
+			continue
+		}
+
+		// The literal value text must be quoted in the excerpt
+		if ext.ValueText != "" && !strings.Contains(ext.Excerpt, ext.ValueText) {
+			log.Printf("Synthesis Agent: Skipping %q - value text %q not in excerpt", ext.Name, ext.ValueText)
+			continue
+		}
+
+		if ext.Methodology.IsEmpty() {
+			ext.Methodology = nil
 		}
 
 		candidates = append(candidates, models.CandidateStatistic{
-			Name:      ext.Name,
-			Value:     ext.Value,
-			Unit:      ext.Unit,
-			Source:    result.Domain,
-			SourceURL: result.URL,
-			Excerpt:   ext.Excerpt,
+			Name:          ext.Name,
+			Value:         ext.Value,
+			ValueText:     ext.ValueText,
+			ValueKind:     kind,
+			ValueMin:      ext.ValueMin,
+			ValueMax:      ext.ValueMax,
+			Unit:          ext.Unit,
+			Period:        ext.Period,
+			Geography:     ext.Geography,
+			Population:    ext.Population,
+			Methodology:   ext.Methodology,
+			Source:        result.Domain,
+			SourceURL:     result.URL,
+			Excerpt:       ext.Excerpt,
+			PromptID:      used.ID,
+			PromptVariant: used.Variant,
+			PromptVersion: used.Version,
 		})
+		candidates[len(candidates)-1].NormalizeValue()
 	}
 
 	return candidates, nil
 }
 
-// extractJSONFromMarkdown removes markdown code fences and extra text from LLM response
-func extractJSONFromMarkdown(response string) string {
-	response = strings.TrimSpace(response)
+// Synthesize processes a synthesis request directly. Search results are fetched
+// and extracted by a pool of workers (SYNTHESIS_WORKERS, at most
+// SYNTHESIS_PER_HOST fetches per host) within SYNTHESIS_TIMEOUT; results are
+// collected in search order, so candidates and early stopping are the same as
+// processing the sources one by one.
+func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisRequest) (*models.SynthesisResponse, error) { // nolint:unparam // error return kept for future usage
+	log.Printf("Synthesis Agent: Processing %d search results for topic: %s", len(req.SearchResults), req.Topic)
 
-	// Try to find JSON array in the response
-	startIdx := strings.Index(response, "[")
-	if startIdx == -1 {
-		return response // No array found, return as-is
+	after, before, err := req.DateRange()
+	if err != nil {
+		return nil, err
 	}
 
-	// Find the matching closing bracket
-	endIdx := strings.LastIndex(response, "]")
-	if endIdx == -1 || endIdx < startIdx {
-		return response // No valid closing bracket
+	if timeout := sa.Cfg.SynthesisTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
 	}
-
-	// Extract just the JSON array
-	jsonStr := response[startIdx : endIdx+1]
-	return strings.TrimSpace(jsonStr)
-}
-
-// Synthesize processes a synthesis request directly
-func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisRequest) (*models.SynthesisResponse, error) { // nolint:unparam // error return kept for future usage
-	log.Printf("Synthesis Agent: Processing %d search results for topic: %s", len(req.SearchResults), req.Topic)
+	// Cancelled on early stop, abandoning sources still in flight
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	var candidates []models.CandidateStatistic
+	var mismatches []models.ScanMismatch
+	irrelevant := 0
+	outOfRange := 0
 	pagesProcessed := 0
 	minPagesToProcess := 15 // Process at least 15 pages for comprehensive coverage (increased from 5)
 
-	// Analyze each search result
-	for _, result := range req.SearchResults {
+	// The same report often appears under several URLs (tracking links, AMP pages,
+	// mirrors); analyze it once
+	dedup := canon.NewDedup()
+	duplicates := 0
+
+	// Queue the sources to analyze, in search order, skipping domains blocked
+	// by the registry or denied by the request
+	registry := sa.reputation.WithOverrides(req.AllowDomains, req.DenyDomains)
+	blocked := make([]bool, len(req.SearchResults))
+	sameURL := make([]string, len(req.SearchResults))
+	var jobs []sourceJob
+	prev := make(chan struct{})
+	close(prev)
+	for i, result := range req.SearchResults {
+		if registry.IsBlocked(result.URL) {
+			blocked[i] = true
+			continue
+		}
+		if first, dup := dedup.SeenURL(result.URL); dup {
+			sameURL[i] = first
+			continue
+		}
+		job := sourceJob{index: i, result: result, prev: prev, turn: make(chan struct{})}
+		jobs = append(jobs, job)
+		prev = job.turn
+	}
+
+	workers := max(1, sa.Cfg.SynthesisWorkers)
+	hosts := newHostLimiter(sa.Cfg.SynthesisPerHost)
+	queue := make(chan sourceJob)
+	results := make(chan sourceResult, len(jobs))
+	for w := 0; w < min(workers, len(jobs)); w++ {
+		go func() {
+			for job := range queue {
+				results <- sa.analyzeSource(ctx, req, job, after, before, hosts, dedup)
+			}
+		}()
+	}
+	go func() {
+		defer close(queue)
+		for _, job := range jobs {
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Collect the results in search order
+	done := map[int]sourceResult{}
+collect:
+	for i, result := range req.SearchResults {
+		if blocked[i] {
+			log.Printf("Synthesis Agent: Skipping blocked source %s", result.Domain)
+			continue
+		}
+
 		// Stop only if we have enough candidates AND processed minimum pages
 		if len(candidates) >= req.MaxStatistics && req.MaxStatistics > 0 && pagesProcessed >= minPagesToProcess {
 			log.Printf("Synthesis Agent: Reached max statistics (%d) after processing %d pages", req.MaxStatistics, pagesProcessed)
 			break
 		}
 
-		// Fetch webpage content using base agent
-		content, err := sa.FetchURL(ctx, result.URL, 1)
-		if err != nil {
-			log.Printf("Failed to fetch %s: %v", result.URL, err)
+		if sameURL[i] != "" {
+			log.Printf("Synthesis Agent: Skipping %s (same URL as %s)", result.URL, sameURL[i])
+			duplicates++
 			continue
 		}
 
-		// Extract statistics using LLM
-		stats, err := sa.extractStatisticsWithLLM(ctx, req.Topic, result, content)
-		if err != nil {
-			log.Printf("Failed to extract statistics from %s: %v", result.URL, err)
+		res, ok := done[i]
+		for !ok {
+			select {
+			case r := <-results:
+				done[r.index] = r
+				res, ok = done[i]
+			case <-ctx.Done():
+				log.Printf("Synthesis Agent: Deadline reached after processing %d pages", pagesProcessed)
+				break collect
+			}
+		}
+		delete(done, i)
+
+		if res.duplicateOf != "" {
+			log.Printf("Synthesis Agent: Skipping %s (same page as %s)", result.URL, res.duplicateOf)
+			duplicates++
+			continue
+		}
+		if res.outOfRange {
+			outOfRange++
+			continue
+		}
+		if res.err != nil {
 			continue
 		}
+		stats := res.stats
+		mismatches = append(mismatches, res.mismatches...)
+		irrelevant += res.irrelevant
 
 		pagesProcessed++
 
@@ -323,12 +563,17 @@ func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisR
 			break
 		}
 	}
+	cancel()
 
 	response := &models.SynthesisResponse{
-		Topic:           req.Topic,
-		Candidates:      candidates,
-		SourcesAnalyzed: min(len(req.SearchResults), len(candidates)/2+1),
-		Timestamp:       time.Now(),
+		Topic:             req.Topic,
+		Candidates:        candidates,
+		SourcesAnalyzed:   min(len(req.SearchResults), len(candidates)/2+1),
+		DuplicatesSkipped: duplicates,
+		ScanMismatches:    mismatches,
+		RelevanceFiltered: irrelevant,
+		DateFiltered:      outOfRange,
+		Timestamp:         time.Now(),
 	}
 
 	log.Printf("Synthesis Agent: Completed with %d candidates from %d sources",
@@ -337,6 +582,24 @@ func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisR
 	return response, nil
 }
 
+// prepareRequest sets the defaults of a synthesis request and rejects a prompt
+// variant synthesis.extract doesn't have or an invalid date range
+func (sa *SynthesisAgent) prepareRequest(req *models.SynthesisRequest) error {
+	if req.MinStatistics == 0 {
+		req.MinStatistics = 5
+	}
+	if req.MaxStatistics == 0 {
+		req.MaxStatistics = 20
+	}
+	if req.PromptVariant != "" {
+		if _, err := sa.prompts.Get(prompts.SynthesisExtract, req.PromptVariant); err != nil {
+			return err
+		}
+	}
+	_, _, err := req.DateRange()
+	return err
+}
+
 // HandleSynthesisRequest is the HTTP handler
 func (sa *SynthesisAgent) HandleSynthesisRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -350,12 +613,9 @@ func (sa *SynthesisAgent) HandleSynthesisRequest(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Set defaults
-	if req.MinStatistics == 0 {
-		req.MinStatistics = 5
-	}
-	if req.MaxStatistics == 0 {
-		req.MaxStatistics = 20
+	if err := sa.prepareRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	resp, err := sa.Synthesize(r.Context(), &req)
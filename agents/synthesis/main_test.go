@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/tool"
+
+	agentbase "github.com/grokify/stats-agent-team/pkg/agent"
+	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/llm"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+	"github.com/grokify/stats-agent-team/pkg/reputation"
+)
+
+func TestExtractWithModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string // Kept statistics as "name=value"
+	}{
+		{"point", `[{"name": "EV sales", "value": 14000000, "value_text": "14 million", "excerpt": "sales neared 14 million"}]`,
+			"EV sales=1.4e+07"},
+		{"zero with value text", `[{"name": "Firms without AI", "value": 0, "value_text": "0%", "excerpt": "0% of respondents"}]`,
+			"Firms without AI=0"},
+		{"zero in excerpt", `[{"name": "GDP growth", "value": 0, "excerpt": "GDP growth was 0.0 in the quarter"}]`,
+			"GDP growth=0"},
+		{"no excerpt", `[{"name": "EV sales", "value": 14000000, "value_text": "14 million", "excerpt": ""}]`,
+			""},
+		{"value missing", `[{"name": "EV sales", "value": 0, "excerpt": "sales rose sharply"}]`,
+			""},
+		{"value text not quoted", `[{"name": "EV sales", "value": 14000000, "value_text": "14 million", "excerpt": "sales neared 13 million"}]`,
+			""},
+		{"range without ends", `[{"name": "AI use", "value": 79, "value_kind": "range", "value_text": "79-96%", "excerpt": "79-96% of firms"}]`,
+			"AI use=79"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := &SynthesisAgent{BaseAgent: &agentbase.BaseAgent{Cfg: &config.Config{}}, prompts: prompts.Default()}
+			used, _ := sa.prompts.Get(prompts.SynthesisExtract, "")
+			stats, err := sa.extractWithModel(context.Background(), &ensembleModel{response: tt.response}, "Extract.", used, models.SearchResult{URL: "https://example.org", Domain: "example.org"})
+			if err != nil {
+				t.Fatalf("extractWithModel: %v", err)
+			}
+			var got []string
+			for _, s := range stats {
+				got = append(got, s.Name+"="+fmt.Sprint(s.Value))
+			}
+			if strings.Join(got, ", ") != tt.want {
+				t.Errorf("got %q, want %q", strings.Join(got, ", "), tt.want)
+			}
+		})
+	}
+}
+
+// toolContext is a tool.Context carrying only a context.Context
+type toolContext struct {
+	tool.Context
+	ctx context.Context
+}
+
+func (c toolContext) Deadline() (time.Time, bool) { return c.ctx.Deadline() }
+func (c toolContext) Done() <-chan struct{}       { return c.ctx.Done() }
+func (c toolContext) Err() error                  { return c.ctx.Err() }
+func (c toolContext) Value(key any) any           { return c.ctx.Value(key) }
+
+func TestSynthesisToolHandler(t *testing.T) {
+	var fetched atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><meta property="article:published_time" content="2024-05-01"></head><body><p>Electric car sales neared 14 million in 2023.</p></body></html>`))
+	}))
+	defer srv.Close()
+
+	m := &ensembleModel{response: `[{"name": "EV sales", "value": 14000000, "value_text": "14 million", "excerpt": "Electric car sales neared 14 million in 2023."}]`}
+	cfg := &config.Config{LLMProvider: "gemini", SynthesisWorkers: 2}
+	sa := &SynthesisAgent{
+		BaseAgent:  &agentbase.BaseAgent{Cfg: cfg, Client: srv.Client(), Model: m, ModelFactory: llm.NewModelFactory(cfg)},
+		reputation: reputation.Default(),
+		prompts:    prompts.Default(),
+	}
+	ctx := toolContext{ctx: context.Background()}
+	results := []models.SearchResult{
+		{URL: "https://www.quora.com/How-many-EVs-were-sold", Domain: "quora.com"},
+		{URL: srv.URL + "/report", Domain: "127.0.0.1"},
+	}
+
+	tests := []struct {
+		name        string
+		input       SynthesisInput
+		wantFetches int32
+		wantStats   int
+		wantErr     string
+	}{
+		{"blocked domain skipped", SynthesisInput{Topic: "EV sales", SearchResults: results}, 1, 1, ""},
+		{"denied domain skipped", SynthesisInput{Topic: "EV sales", SearchResults: results, DenyDomains: []string{"127.0.0.1"}}, 0, 0, ""},
+		{"out of date range skipped", SynthesisInput{Topic: "EV sales", SearchResults: results[1:], PublishedAfter: "2030-01-01"}, 1, 0, ""},
+		{"unknown prompt variant", SynthesisInput{Topic: "EV sales", SearchResults: results, PromptVariant: "verbose"}, 0, 0, `no "verbose" variant`},
+		{"invalid date", SynthesisInput{Topic: "EV sales", SearchResults: results, PublishedBefore: "soon"}, 0, 0, "published_before"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched.Store(0)
+			out, err := sa.synthesisToolHandler(ctx, tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("synthesisToolHandler: %v", err)
+			}
+			if n := fetched.Load(); n != tt.wantFetches {
+				t.Errorf("fetched %d pages, want %d", n, tt.wantFetches)
+			}
+			if len(out.Candidates) != tt.wantStats {
+				t.Errorf("got %d candidates, want %d", len(out.Candidates), tt.wantStats)
+			}
+		})
+	}
+}
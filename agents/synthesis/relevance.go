@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/grokify/stats-agent-team/pkg/models"
+)
+
+// scoreRelevance records each candidate's relevance to the topic. Scoring is
+// best effort: if the scorer fails, the candidates are returned unscored. An
+// LLM scorer uses the agent's main model even with an extraction ensemble.
+func (sa *SynthesisAgent) scoreRelevance(ctx context.Context, topic string, candidates []models.CandidateStatistic) []models.CandidateStatistic {
+	if sa.relevance == nil || len(candidates) == 0 {
+		return candidates
+	}
+	scores, err := sa.relevance.Score(ctx, topic, candidates)
+	if err != nil {
+		log.Printf("Synthesis Agent: Relevance scoring failed, keeping candidates unscored: %v", err)
+		return candidates
+	}
+	for i := range candidates {
+		score := scores[i]
+		candidates[i].Relevance = &score
+	}
+	return candidates
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	agentbase "github.com/grokify/stats-agent-team/pkg/agent"
+	"github.com/grokify/stats-agent-team/pkg/htmltext"
+	"github.com/grokify/stats-agent-team/pkg/llm"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+// maxTableCells caps the numeric cells of one page offered to the LLM
+const maxTableCells = 400
+
+// tableCell is a numeric table cell offered to the LLM, identified by ID
+type tableCell struct {
+	id    string
+	table int
+	cell  htmltext.Cell
+}
+
+// extractTableStatistics turns the cells of a page's HTML tables into statistics.
+// Tables are parsed deterministically; the LLM only selects the cells relevant to
+// the topic and names them, so values and excerpts come straight from the table.
+// Each excerpt is the cell reconstructed as "row header / column header: value",
+// which verification matches against the same table. The selection always uses
+// the agent's main model, not the extraction ensemble: values can't disagree
+// across models here, only which cells are picked.
+func (sa *SynthesisAgent) extractTableStatistics(ctx context.Context, topic, variant string, result models.SearchResult, page *agentbase.Page) ([]models.CandidateStatistic, error) {
+	if len(page.PDFPages) > 0 || !htmltext.IsHTML(page.Body, page.ContentType) {
+		return nil, nil
+	}
+	tables, err := htmltext.Tables(page.Body, page.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tables: %w", err)
+	}
+
+	// Only cells holding numbers can become statistics
+	cells := map[string]tableCell{}
+	var listing strings.Builder
+	for _, t := range tables {
+		header := false
+		for i, c := range t.Cells {
+			if len(cells) >= maxTableCells {
+				break
+			}
+			if _, ok := models.ParseValueText(c.Value); !ok {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&listing, "\nTable %d", t.Index)
+				if t.Caption != "" {
+					fmt.Fprintf(&listing, ": %s", t.Caption)
+				}
+				listing.WriteString("\n")
+				header = true
+			}
+			id := "t" + strconv.Itoa(t.Index) + "c" + strconv.Itoa(i+1)
+			cells[id] = tableCell{id: id, table: t.Index, cell: c}
+			fmt.Fprintf(&listing, "[%s] %s\n", id, c.Excerpt())
+		}
+	}
+	if len(cells) == 0 {
+		return nil, nil
+	}
+	log.Printf("Synthesis Agent: Selecting from %d table cells on %s", len(cells), result.URL)
+
+	// Table prompts need not have every extraction variant
+	prompt, used, err := sa.prompts.Render(prompts.SynthesisTables, sa.prompts.Preferred(prompts.SynthesisTables, variant), struct{ Topic, URL, Domain, Cells string }{
+		topic, result.URL, result.Domain, listing.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type CellSelection struct {
+		Cell       string            `json:"cell"`
+		Name       string            `json:"name"`
+		Unit       string            `json:"unit,omitempty"`
+		ValueKind  string            `json:"value_kind,omitempty"`
+		Period     *models.Period    `json:"period,omitempty"`
+		Geography  *models.Geography `json:"geography,omitempty"`
+		Population string            `json:"population,omitempty"`
+	}
+
+	selections, err := llm.GenerateJSON[[]CellSelection](ctx, sa.Model, prompt, llm.JSONOptions{MaxRepairs: sa.Cfg.LLMMaxRepairs, Prompts: sa.prompts})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.CandidateStatistic, 0, len(selections))
+	selected := map[string]bool{}
+	for _, sel := range selections {
+		tc, ok := cells[strings.TrimSpace(sel.Cell)]
+		if !ok || selected[tc.id] || sel.Name == "" {
+			continue
+		}
+		selected[tc.id] = true
+
+		valueText := strings.TrimSpace(tc.cell.Value)
+		numbers := models.ExtractNumbers(valueText)
+		kind := models.ParseValueKind(sel.ValueKind)
+		var valueMin, valueMax *float64
+		if kind == models.ValueKindRange {
+			if len(numbers) < 2 {
+				kind = models.ValueKindPoint
+			} else {
+				valueMin, valueMax = &numbers[0], &numbers[1]
+			}
+		}
+
+		candidates = append(candidates, models.CandidateStatistic{
+			Name:          sel.Name,
+			Value:         numbers[0],
+			ValueText:     valueText,
+			ValueKind:     kind,
+			ValueMin:      valueMin,
+			ValueMax:      valueMax,
+			Unit:          sel.Unit,
+			Period:        sel.Period,
+			Geography:     sel.Geography,
+			Population:    sel.Population,
+			Source:        result.Domain,
+			SourceURL:     result.URL,
+			Table:         tc.table,
+			Excerpt:       tc.cell.Excerpt(),
+			PromptID:      used.ID,
+			PromptVariant: used.Variant,
+			PromptVersion: used.Version,
+		})
+		// Headers state magnitudes ("in millions") the cell value lacks
+		candidates[len(candidates)-1].NormalizeValue()
+	}
+	return candidates, nil
+}
+
+// dropTableDuplicates removes text candidates that restate a table candidate:
+// the same value, with the row header and value text in the excerpt (the
+// rendered table row). Table candidates are kept since they verify exactly.
+func dropTableDuplicates(textStats, tableStats []models.CandidateStatistic) []models.CandidateStatistic {
+	if len(tableStats) == 0 {
+		return textStats
+	}
+	kept := textStats[:0]
+	for _, s := range textStats {
+		excerpt := normalizeExcerpt(s.Excerpt)
+		dup := false
+		for _, t := range tableStats {
+			rowHeader, _, _ := strings.Cut(t.Excerpt, " / ")
+			if models.ValuesEqual(s.Value, t.Value) &&
+				strings.Contains(excerpt, normalizeExcerpt(t.ValueText)) &&
+				strings.Contains(excerpt, normalizeExcerpt(rowHeader)) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
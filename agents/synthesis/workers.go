@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/stats-agent-team/pkg/canon"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+	"github.com/grokify/stats-agent-team/pkg/relevance"
+)
+
+// sourceJob is a search result queued for analysis
+type sourceJob struct {
+	index  int // Position in the search results
+	result models.SearchResult
+	prev   <-chan struct{} // Closed once the previous queued source has passed its duplicate check
+	turn   chan struct{}   // Closed once this source has passed its duplicate check
+}
+
+// sourceResult is the outcome of analyzing one search result
+type sourceResult struct {
+	index       int
+	stats       []models.CandidateStatistic
+	mismatches  []models.ScanMismatch
+	irrelevant  int  // Candidates dropped for scoring below the minimum relevance
+	outOfRange  bool // Published outside the request's date range
+	err         error
+	duplicateOf string // URL of the earlier source this page repeats
+}
+
+// analyzeSource fetches a search result and extracts its statistics. Fetches run
+// in parallel, but pages are checked for duplicates in search order (each job
+// waits for the previous one), so the earliest copy of a page is the one analyzed
+// whatever order the fetches complete in. Pages published outside after and
+// before (zero for no bound) are skipped.
+func (sa *SynthesisAgent) analyzeSource(ctx context.Context, req *models.SynthesisRequest, job sourceJob, after, before time.Time, hosts *hostLimiter, dedup *canon.Dedup) sourceResult {
+	res := sourceResult{index: job.index}
+	passed := false
+	pass := func() {
+		if !passed {
+			close(job.turn)
+			passed = true
+		}
+	}
+	defer pass()
+
+	release, err := hosts.acquire(ctx, sourceHost(job.result))
+	if err != nil {
+		res.err = err
+		return res
+	}
+	page, err := sa.FetchPage(ctx, job.result.URL, 1)
+	release()
+	if err != nil {
+		log.Printf("Failed to fetch %s: %v", job.result.URL, err)
+		res.err = err
+		return res
+	}
+
+	select {
+	case <-job.prev:
+	case <-ctx.Done():
+		res.err = ctx.Err()
+		return res
+	}
+	// Pages can declare their canonical URL or repeat another page's content
+	if first, dup := dedup.SeenPage(job.result.URL, canon.LinkCanonical(job.result.URL, string(page.Body)), page.Text); dup {
+		res.duplicateOf = first
+		return res
+	}
+	pass()
+
+	// Search providers date few results; the page's metadata usually has the date
+	published := page.Meta.Published
+	if published == "" {
+		published = job.result.PublishedDate
+	}
+	if !publishedWithin(published, after, before) {
+		log.Printf("Synthesis Agent: Skipping %s (published %s, outside the requested dates)", job.result.URL, published)
+		res.outOfRange = true
+		return res
+	}
+
+	// Extract statistics from the page's main content using LLM
+	res.stats, res.mismatches, res.err = sa.extractStatisticsWithLLM(ctx, req.Topic, sa.promptVariant(req), job.result, page)
+	if res.err != nil {
+		log.Printf("Failed to extract statistics from %s: %v", job.result.URL, res.err)
+		return res
+	}
+
+	// Score relevance only for requests that filter on it, as the LLM scorer
+	// costs a call per source. Score here, so off-topic candidates don't count
+	// toward early stopping.
+	if req.MinRelevance > 0 {
+		res.stats, res.irrelevant = relevance.Filter(sa.scoreRelevance(ctx, req.Topic, res.stats), req.MinRelevance)
+		if res.irrelevant > 0 {
+			log.Printf("Synthesis Agent: Dropped %d statistics from %s below relevance %.2f", res.irrelevant, job.result.Domain, req.MinRelevance)
+		}
+	}
+	return res
+}
+
+// publishedWithin reports whether a YYYY-MM-DD publication date falls on or
+// after after and before before. Unknown dates are within any range, as in the
+// search filters.
+func publishedWithin(published string, after, before time.Time) bool {
+	t, err := time.Parse(models.DateLayout, published)
+	if err != nil {
+		return true
+	}
+	return (after.IsZero() || !t.Before(after)) && (before.IsZero() || t.Before(before))
+}
+
+// promptVariant returns the extraction prompt variant a request asked for
+// (prepareRequest rejects variants synthesis.extract doesn't have), else PROMPT_VARIANT
+// if synthesis.extract has it, else the default
+func (sa *SynthesisAgent) promptVariant(req *models.SynthesisRequest) string {
+	if req.PromptVariant != "" {
+		return req.PromptVariant
+	}
+	return sa.prompts.Preferred(prompts.SynthesisExtract, sa.Cfg.PromptVariant)
+}
+
+// sourceHost returns the host a search result is fetched from
+func sourceHost(result models.SearchResult) string {
+	if u, err := url.Parse(result.URL); err == nil && u.Hostname() != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return result.Domain
+}
+
+// hostLimiter caps the concurrent fetches from each host, so a run with many
+// results from one site does not hammer it
+type hostLimiter struct {
+	limit int // Fetches per host at once; 0 = unlimited
+	mu    sync.Mutex
+	sems  map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{limit: limit, sems: map[string]chan struct{}{}}
+}
+
+// acquire waits for a fetch slot for host and returns the function releasing it
+func (h *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if h.limit <= 0 {
+		return func() {}, nil
+	}
+	h.mu.Lock()
+	sem, ok := h.sems[host]
+	if !ok {
+		sem = make(chan struct{}, h.limit)
+		h.sems[host] = sem
+	}
+	h.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishedWithin(t *testing.T) {
+	day := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+	tests := []struct {
+		name          string
+		published     string
+		after, before time.Time
+		want          bool
+	}{
+		{"no bounds", "2019-05-01", time.Time{}, time.Time{}, true},
+		{"unknown date", "", day("2023-01-01"), day("2025-01-01"), true},
+		{"unparsable date", "spring 2020", day("2023-01-01"), time.Time{}, true},
+		{"inside", "2024-03-05", day("2023-01-01"), day("2025-01-01"), true},
+		{"on after bound", "2023-01-01", day("2023-01-01"), time.Time{}, true},
+		{"before after bound", "2022-12-31", day("2023-01-01"), time.Time{}, false},
+		{"on before bound", "2025-01-01", time.Time{}, day("2025-01-01"), false},
+		{"after before bound", "2025-06-01", day("2023-01-01"), day("2025-01-01"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := publishedWithin(tt.published, tt.after, tt.before); got != tt.want {
+				t.Errorf("publishedWithin(%q) = %v, want %v", tt.published, got, tt.want)
+			}
+		})
+	}
+}
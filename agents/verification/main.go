@@ -17,7 +17,9 @@ import (
 
 	agentbase "github.com/grokify/stats-agent-team/pkg/agent"
 	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/htmltext"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
 )
 
 // VerificationAgent uses ADK for validating statistics
@@ -44,6 +46,15 @@ func NewVerificationAgent(cfg *config.Config) (*VerificationAgent, error) {
 		return nil, fmt.Errorf("failed to create base agent: %w", err)
 	}
 
+	promptRegistry, err := prompts.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	instruction, _, err := promptRegistry.Render(prompts.VerificationInstruction, promptRegistry.Preferred(prompts.VerificationInstruction, cfg.PromptVariant), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Printf("Verification Agent: Using %s", base.GetProviderInfo())
 
 	va := &VerificationAgent{
@@ -64,19 +75,8 @@ func NewVerificationAgent(cfg *config.Config) (*VerificationAgent, error) {
 		Name:        "statistics_verification_agent",
 		Model:       base.Model,
 		Description: "Verifies that statistics actually exist in their claimed sources",
-		Instruction: `You are a statistics verification agent. Your job is to:
-1. Fetch the content from the provided source URL
-2. Search for the verbatim excerpt in the source content
-3. Verify the numerical value matches exactly
-4. Check if the source is reputable
-5. Flag any discrepancies, hallucinations, or mismatches
-
-Verification criteria:
-- The exact excerpt must be present in the source
-- The numerical value must match (allowing for reasonable formatting differences)
-- The source must be accessible and legitimate
-- The context must support the claimed statistic`,
-		Tools: []tool.Tool{verifyTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{verifyTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ADK agent: %w", err)
@@ -107,51 +107,97 @@ func (va *VerificationAgent) verifyToolHandler(ctx tool.Context, input Verificat
 func (va *VerificationAgent) verifyStatistic(ctx context.Context, candidate models.CandidateStatistic) models.VerificationResult {
 	log.Printf("Verification Agent: Verifying statistic from %s", candidate.SourceURL)
 
-	// Fetch source content using base agent
-	sourceContent, err := va.FetchURL(ctx, candidate.SourceURL, 1)
+	// Fetch the source's main text, as synthesis saw it, using base agent
+	page, err := va.FetchPage(ctx, candidate.SourceURL, 1)
 	if err != nil {
 		log.Printf("Failed to fetch source: %v", err)
 		return models.VerificationResult{
-			Statistic: &models.Statistic{
-				Name:      candidate.Name,
-				Value:     candidate.Value,
-				Unit:      candidate.Unit,
-				Source:    candidate.Source,
-				SourceURL: candidate.SourceURL,
-				Excerpt:   candidate.Excerpt,
-				Verified:  false,
-				DateFound: time.Now(),
-			},
-			Verified: false,
-			Reason:   fmt.Sprintf("Failed to fetch source: %v", err),
+			Statistic: candidate.ToStatistic(false),
+			Verified:  false,
+			Reason:    fmt.Sprintf("Failed to fetch source: %v", err),
 		}
 	}
 
 	// Simple verification: check if excerpt appears in source
-	verified := strings.Contains(sourceContent, candidate.Excerpt)
+	var verified bool
+	if candidate.Table > 0 {
+		// Table excerpts are reconstructed from a cell; match them against the same table
+		verified = tableHasExcerpt(page, candidate.Table, candidate.Excerpt)
+	} else if len(page.PDFPages) > 0 {
+		// PDF excerpts are matched page by page, ignoring line breaks
+		pageNum := page.PageOf(candidate.Excerpt)
+		verified = pageNum > 0
+		if verified && pageNum != candidate.Page {
+			if candidate.Page > 0 {
+				log.Printf("Verification Agent: Excerpt found on page %d, not page %d, of %s", pageNum, candidate.Page, candidate.SourceURL)
+			}
+			candidate.Page = pageNum
+		}
+	} else {
+		verified = strings.Contains(page.Text, candidate.Excerpt)
+	}
 	reason := ""
 	if !verified {
 		reason = "Excerpt not found in source content"
-	}
-
-	stat := &models.Statistic{
-		Name:      candidate.Name,
-		Value:     candidate.Value,
-		Unit:      candidate.Unit,
-		Source:    candidate.Source,
-		SourceURL: candidate.SourceURL,
-		Excerpt:   candidate.Excerpt,
-		Verified:  verified,
-		DateFound: time.Now(),
+	} else if reason = checkValueInExcerpt(candidate); reason != "" {
+		verified = false
 	}
 
 	return models.VerificationResult{
-		Statistic: stat,
+		Statistic: candidate.ToStatistic(verified),
 		Verified:  verified,
 		Reason:    reason,
 	}
 }
 
+// tableHasExcerpt reports whether the given HTML table of a page (1-based, as
+// numbered by htmltext.Tables) has a cell whose reconstructed excerpt is excerpt
+func tableHasExcerpt(page *agentbase.Page, table int, excerpt string) bool {
+	if !htmltext.IsHTML(page.Body, page.ContentType) {
+		return false
+	}
+	tables, err := htmltext.Tables(page.Body, page.ContentType)
+	if err != nil || table > len(tables) {
+		return false
+	}
+	_, ok := tables[table-1].FindExcerpt(excerpt)
+	return ok
+}
+
+// checkValueInExcerpt confirms the candidate's value appears in its excerpt.
+// The literal value text is matched verbatim and the normalized value is compared
+// against it, applying magnitude words written with the number or in a header
+// such as "(in millions)"; for ranges both ends must be present. Returns a
+// failure reason, or "" if the value matches.
+func checkValueInExcerpt(candidate models.CandidateStatistic) string {
+	// Numbers are looked up in the literal value text when present, else the whole excerpt
+	text := candidate.Excerpt
+	if candidate.ValueText != "" {
+		if !strings.Contains(candidate.Excerpt, candidate.ValueText) {
+			return fmt.Sprintf("Value text %q not found in excerpt", candidate.ValueText)
+		}
+		if _, ok := models.ParseValueText(candidate.ValueText); ok && !models.ValueInText(candidate.Value, candidate.ValueText, candidate.Excerpt) {
+			return fmt.Sprintf("Value %v does not match value text %q", candidate.Value, candidate.ValueText)
+		}
+		text = candidate.ValueText
+	}
+
+	if candidate.ValueKind == models.ValueKindRange && candidate.ValueMin != nil && candidate.ValueMax != nil {
+		if !models.ValueInText(*candidate.ValueMin, text, candidate.Excerpt) {
+			return fmt.Sprintf("Range lower end %v not found in excerpt", *candidate.ValueMin)
+		}
+		if !models.ValueInText(*candidate.ValueMax, text, candidate.Excerpt) {
+			return fmt.Sprintf("Range upper end %v not found in excerpt", *candidate.ValueMax)
+		}
+		return ""
+	}
+
+	if !models.ValueInText(candidate.Value, text, candidate.Excerpt) {
+		return fmt.Sprintf("Value %v not found in excerpt", candidate.Value)
+	}
+	return ""
+}
+
 // Verify processes a verification request
 //
 //nolint:unparam // error return kept for API consistency
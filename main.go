@@ -8,11 +8,13 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 
 	"github.com/grokify/stats-agent-team/pkg/config"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/reputation"
 )
 
 // Options defines the CLI options structure
@@ -40,14 +42,51 @@ type SearchCommand struct {
 	Direct        bool   `short:"d" long:"direct" description:"Use direct LLM search (faster, like ChatGPT)"`
 	DirectVerify  bool   `long:"direct-verify" description:"Verify LLM claims with verification agent (requires --direct and verification agent running)"`
 
+	// Source reputation overrides
+	AllowDomains []string `long:"allow-domain" description:"Treat this domain as reputable for this search (repeatable)"`
+	DenyDomains  []string `long:"deny-domain" description:"Never use this domain as a source for this search (repeatable)"`
+
+	// Scope filters
+	Countries  []string `long:"country" description:"Only keep statistics for this ISO country code (repeatable, e.g. --country US)"`
+	PeriodFrom int      `long:"period-from" description:"Only keep statistics whose reference period ends in or after this year"`
+	PeriodTo   int      `long:"period-to" description:"Only keep statistics whose reference period starts in or before this year"`
+
+	MinRelevance  float64 `long:"min-relevance" description:"Drop statistics scoring below this relevance to the topic (0-1)"`
+	PromptVariant string  `long:"prompt-variant" description:"Extraction prompt variant (default: PROMPT_VARIANT or \"default\")"`
+
+	// Search filters
+	Language        string   `long:"language" description:"Search language as an ISO 639-1 code (default: en)"`
+	SearchCountry   string   `long:"search-country" description:"Search market as an ISO 3166-1 alpha-2 code (default: US)"`
+	PublishedAfter  string   `long:"published-after" description:"Only use sources published on or after this date (YYYY-MM-DD)"`
+	PublishedBefore string   `long:"published-before" description:"Only use sources published before this date (YYYY-MM-DD)"`
+	SiteInclude     []string `long:"site" description:"Only search this domain (repeatable, e.g. --site cdc.gov)"`
+	SiteExclude     []string `long:"exclude-site" description:"Never search this domain (repeatable)"`
+
 	// Orchestrator options
 	OrchestratorURL string `long:"orchestrator-url" description:"Orchestrator URL (overrides env var)" env:"ORCHESTRATOR_URL"`
 }
 
+// searchOptions returns the search filters given on the command line
+func (cmd *SearchCommand) searchOptions() models.SearchOptions {
+	return models.SearchOptions{
+		Language:        cmd.Language,
+		Country:         cmd.SearchCountry,
+		PublishedAfter:  cmd.PublishedAfter,
+		PublishedBefore: cmd.PublishedBefore,
+		SiteInclude:     cmd.SiteInclude,
+		SiteExclude:     cmd.SiteExclude,
+	}
+}
+
 // Execute runs the search command
 func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 	topic := cmd.Args.Topic
 
+	opts := cmd.searchOptions()
+	if _, _, err := opts.DateRange(); err != nil {
+		return err
+	}
+
 	cfg := config.LoadConfig()
 
 	fmt.Printf("Searching for statistics about: %s\n", topic)
@@ -88,6 +127,14 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 		MinVerifiedStats: cmd.MinStats,
 		MaxCandidates:    cmd.MaxCandidates,
 		ReputableOnly:    cmd.ReputableOnly,
+		AllowDomains:     cmd.AllowDomains,
+		DenyDomains:      cmd.DenyDomains,
+		Countries:        cmd.Countries,
+		PeriodFrom:       cmd.PeriodFrom,
+		PeriodTo:         cmd.PeriodTo,
+		MinRelevance:     cmd.MinRelevance,
+		PromptVariant:    cmd.PromptVariant,
+		SearchOptions:    cmd.searchOptions(),
 	}
 
 	// Call orchestration agent
@@ -99,6 +146,7 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 	// Handle partial results with retry logic
 	allStatistics := resp.Statistics
 	totalVerified := resp.VerifiedCount
+	totalMerged := resp.MergedCount
 	retryCount := 0
 	maxRetries := 3
 
@@ -134,6 +182,14 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 			MinVerifiedStats: stillNeeded,
 			MaxCandidates:    cmd.MaxCandidates + (retryCount * 20), // Increase search space
 			ReputableOnly:    cmd.ReputableOnly,
+			AllowDomains:     cmd.AllowDomains,
+			DenyDomains:      cmd.DenyDomains,
+			Countries:        cmd.Countries,
+			PeriodFrom:       cmd.PeriodFrom,
+			PeriodTo:         cmd.PeriodTo,
+			MinRelevance:     cmd.MinRelevance,
+			PromptVariant:    cmd.PromptVariant,
+			SearchOptions:    cmd.searchOptions(),
 		}
 
 		continueResp, err := callOrchestrator(cfg, continueReq)
@@ -143,13 +199,20 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 			break
 		}
 
-		// Merge new statistics with existing ones
-		allStatistics = append(allStatistics, continueResp.Statistics...)
-		totalVerified += continueResp.VerifiedCount
+		// Merge new statistics with existing ones, counting a fact found by both
+		// searches once
+		merged := append(allStatistics, continueResp.Statistics...)
+		if registry, err := reputation.LoadFromConfig(cfg); err == nil {
+			registry.WithOverrides(cmd.AllowDomains, cmd.DenyDomains).RankStatistics(merged)
+		}
+		allStatistics = models.ClusterStatistics(merged)
+		totalVerified = len(allStatistics)
+		totalMerged += continueResp.MergedCount + len(merged) - len(allStatistics)
 
 		// Update response for next iteration
 		resp = continueResp
 		resp.VerifiedCount = totalVerified
+		resp.MergedCount = totalMerged
 		resp.Statistics = allStatistics
 		resp.Partial = totalVerified < req.MinVerifiedStats
 
@@ -205,6 +268,7 @@ OPENAI_API_KEY        API key for OpenAI
 SEARCH_PROVIDER       Search provider (serper, serpapi)
 SERPER_API_KEY        API key for Serper
 SERPAPI_API_KEY       API key for SerpAPI
+REPUTATION_FILE       Domain reputation registry (YAML/JSON, optional)
 ORCHESTRATOR_URL      Orchestrator URL (default: http://localhost:8000)
 
 EXAMPLES:
@@ -212,6 +276,7 @@ stats-agent search "climate change"
 stats-agent search "AI adoption rates" --min-stats 15
 stats-agent search "cybersecurity 2024" --output json
 stats-agent search "renewable energy" --reputable-only
+stats-agent search "broadband access" --country US --period-from 2022
 `
 
 	// Parse arguments
@@ -335,6 +400,9 @@ func printResults(resp *models.OrchestrationResponse, outputFormat string) {
 	fmt.Printf("Topic: %s\n", resp.Topic)
 	fmt.Printf("Found: %d verified statistics (from %d candidates)\n", resp.VerifiedCount, resp.TotalCandidates)
 	fmt.Printf("Failed verification: %d\n", resp.FailedCount)
+	if resp.FilteredCount > 0 {
+		fmt.Printf("Outside requested scope: %d\n", resp.FilteredCount)
+	}
 	fmt.Printf("Timestamp: %s\n\n", resp.Timestamp.Format("2006-01-02 15:04:05"))
 
 	if len(resp.Statistics) == 0 {
@@ -360,10 +428,47 @@ func printResults(resp *models.OrchestrationResponse, outputFormat string) {
 	fmt.Println()
 	for i, stat := range resp.Statistics {
 		fmt.Printf("%d. %s\n", i+1, stat.Name)
-		fmt.Printf("   Value: %v %s\n", stat.Value, stat.Unit)
-		fmt.Printf("   Source: %s\n", stat.Source)
-		fmt.Printf("   URL: %s\n", stat.SourceURL)
+		fmt.Printf("   Value: %s\n", stat.DisplayValue())
+		if stat.ValueText != "" {
+			fmt.Printf("   As Written: %s\n", stat.ValueText)
+		}
+		if stat.Period != nil {
+			fmt.Printf("   Period: %s\n", stat.Period)
+		}
+		if stat.Geography != nil {
+			fmt.Printf("   Geography: %s\n", stat.Geography)
+		}
+		if stat.Population != "" {
+			fmt.Printf("   Population: %s\n", stat.Population)
+		}
+		if stat.Methodology != nil {
+			fmt.Printf("   Methodology: %s\n", stat.Methodology)
+		}
+		if stat.SourceTier != "" {
+			fmt.Printf("   Source: %s (%s)\n", stat.Source, stat.SourceTier)
+		} else {
+			fmt.Printf("   Source: %s\n", stat.Source)
+		}
+		if stat.Author != "" {
+			fmt.Printf("   Author: %s\n", stat.Author)
+		}
+		if stat.PublishedDate != "" {
+			fmt.Printf("   Published: %s\n", stat.PublishedDate)
+		}
+		if stat.ModifiedDate != "" {
+			fmt.Printf("   Updated: %s\n", stat.ModifiedDate)
+		}
+		fmt.Printf("   URL: %s\n", stat.CitationURL())
+		if stat.Page > 0 {
+			fmt.Printf("   Page: %d\n", stat.Page)
+		}
 		fmt.Printf("   Excerpt: \"%s\"\n", stat.Excerpt)
+		for _, c := range stat.Corroborating {
+			fmt.Printf("   Also Reported By: %s (%s)\n", c.Source, c.SourceURL)
+		}
+		if len(stat.AgreedModels) > 0 {
+			fmt.Printf("   Extracted By: %s\n", strings.Join(stat.AgreedModels, ", "))
+		}
 		fmt.Printf("   Verified: ✓\n")
 		fmt.Printf("   Date Found: %s\n\n", stat.DateFound.Format("2006-01-02"))
 	}
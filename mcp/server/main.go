@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -15,6 +16,7 @@ import (
 	"github.com/grokify/stats-agent-team/pkg/config"
 	"github.com/grokify/stats-agent-team/pkg/models"
 	"github.com/grokify/stats-agent-team/pkg/orchestration"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
 )
 
 const (
@@ -23,10 +25,23 @@ const (
 )
 
 type SearchStatisticsParams struct {
-	Topic            string `json:"topic"`
-	MinVerifiedStats int    `json:"min_verified_stats,omitempty"`
-	MaxCandidates    int    `json:"max_candidates,omitempty"`
-	ReputableOnly    bool   `json:"reputable_only,omitempty"`
+	Topic            string   `json:"topic"`
+	MinVerifiedStats int      `json:"min_verified_stats,omitempty"`
+	MaxCandidates    int      `json:"max_candidates,omitempty"`
+	ReputableOnly    bool     `json:"reputable_only,omitempty"`
+	AllowDomains     []string `json:"allow_domains,omitempty"`
+	DenyDomains      []string `json:"deny_domains,omitempty"`
+	Countries        []string `json:"countries,omitempty"`
+	PeriodFrom       int      `json:"period_from,omitempty"`
+	PeriodTo         int      `json:"period_to,omitempty"`
+	MinRelevance     float64  `json:"min_relevance,omitempty"`
+	PromptVariant    string   `json:"prompt_variant,omitempty"`
+	Language         string   `json:"language,omitempty"`
+	Country          string   `json:"country,omitempty"`
+	PublishedAfter   string   `json:"published_after,omitempty"`
+	PublishedBefore  string   `json:"published_before,omitempty"`
+	SiteInclude      []string `json:"site_include,omitempty"`
+	SiteExclude      []string `json:"site_exclude,omitempty"`
 }
 
 var einoAgent *orchestration.EinoOrchestrationAgent
@@ -59,6 +74,21 @@ func SearchStatistics(ctx context.Context, req *mcp.CallToolRequest, args Search
 		MinVerifiedStats: args.MinVerifiedStats,
 		MaxCandidates:    args.MaxCandidates,
 		ReputableOnly:    args.ReputableOnly,
+		AllowDomains:     args.AllowDomains,
+		DenyDomains:      args.DenyDomains,
+		Countries:        args.Countries,
+		PeriodFrom:       args.PeriodFrom,
+		PeriodTo:         args.PeriodTo,
+		MinRelevance:     args.MinRelevance,
+		PromptVariant:    args.PromptVariant,
+		SearchOptions: models.SearchOptions{
+			Language:        args.Language,
+			Country:         args.Country,
+			PublishedAfter:  args.PublishedAfter,
+			PublishedBefore: args.PublishedBefore,
+			SiteInclude:     args.SiteInclude,
+			SiteExclude:     args.SiteExclude,
+		},
 	}
 
 	log.Printf("[MCP] Searching for statistics on topic: %s", args.Topic)
@@ -153,6 +183,12 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Load prompts to advertise their variants
+	promptRegistry, err := prompts.LoadFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	}
+
 	// Create Eino orchestration agent
 	einoAgent = orchestration.NewEinoOrchestrationAgent(cfg)
 
@@ -179,28 +215,7 @@ func main() {
 				"The system uses research and verification agents to find and validate statistics from " +
 				"reputable sources (government agencies, academic institutions, research organizations). " +
 				"Returns verified statistics with their sources, URLs, and verbatim excerpts.",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"topic": map[string]interface{}{
-						"type":        "string",
-						"description": "The topic to search statistics for (e.g., 'climate change', 'AI adoption rates', 'cybersecurity threats')",
-					},
-					"min_verified_stats": map[string]interface{}{
-						"type":        "number",
-						"description": "Minimum number of verified statistics to return (default: 10)",
-					},
-					"max_candidates": map[string]interface{}{
-						"type":        "number",
-						"description": "Maximum number of candidate statistics to gather (default: 30)",
-					},
-					"reputable_only": map[string]interface{}{
-						"type":        "boolean",
-						"description": "Only use reputable sources like government, academic, and research organizations (default: true)",
-					},
-				},
-				"required": []string{"topic"},
-			},
+			InputSchema: searchStatisticsSchema(promptRegistry.Variants(prompts.SynthesisExtract)),
 		},
 		SearchStatistics,
 	)
@@ -216,6 +231,92 @@ func main() {
 	}
 }
 
+// searchStatisticsSchema returns the JSON schema of SearchStatisticsParams, listing
+// the given extraction prompt variants
+func searchStatisticsSchema(variants []string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"topic": map[string]interface{}{
+				"type":        "string",
+				"description": "The topic to search statistics for (e.g., 'climate change', 'AI adoption rates', 'cybersecurity threats')",
+			},
+			"min_verified_stats": map[string]interface{}{
+				"type":        "number",
+				"description": "Minimum number of verified statistics to return (default: 10)",
+			},
+			"max_candidates": map[string]interface{}{
+				"type":        "number",
+				"description": "Maximum number of candidate statistics to gather (default: 30)",
+			},
+			"min_relevance": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"maximum":     1,
+				"description": "Drop candidates scoring below this relevance to the topic, from 0 to 1 (default: 0, keep all)",
+			},
+			"prompt_variant": map[string]interface{}{
+				"type":        "string",
+				"description": "Extraction prompt variant, for A/B comparisons; one of: " + strings.Join(variants, ", ") + " (default: PROMPT_VARIANT, else 'default')",
+			},
+			"reputable_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only use reputable sources like government, academic, and research organizations (default: true)",
+			},
+			"allow_domains": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Domains to treat as reputable for this search (e.g., ['mckinsey.com'])",
+			},
+			"deny_domains": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Domains to never use as sources for this search",
+			},
+			"countries": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only return statistics measured in these ISO 3166-1 alpha-2 country codes (e.g., ['US'])",
+			},
+			"period_from": map[string]interface{}{
+				"type":        "number",
+				"description": "Only return statistics whose reference period ends in or after this year (e.g., 2022)",
+			},
+			"period_to": map[string]interface{}{
+				"type":        "number",
+				"description": "Only return statistics whose reference period starts in or before this year",
+			},
+			"language": map[string]interface{}{
+				"type":        "string",
+				"description": "Search language as an ISO 639-1 code (default: 'en')",
+			},
+			"country": map[string]interface{}{
+				"type":        "string",
+				"description": "Search market as an ISO 3166-1 alpha-2 code (default: 'US')",
+			},
+			"published_after": map[string]interface{}{
+				"type":        "string",
+				"description": "Only use sources published on or after this date (YYYY-MM-DD)",
+			},
+			"published_before": map[string]interface{}{
+				"type":        "string",
+				"description": "Only use sources published before this date (YYYY-MM-DD)",
+			},
+			"site_include": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only search these domains (e.g., ['cdc.gov', 'who.int'])",
+			},
+			"site_exclude": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Never search these domains",
+			},
+		},
+		"required": []string{"topic"},
+	}
+}
+
 // formatResponse formats the orchestration response for display
 func formatResponse(result *models.OrchestrationResponse) string {
 	if result == nil {
@@ -227,6 +328,9 @@ func formatResponse(result *models.OrchestrationResponse) string {
 	output += fmt.Sprintf("**Verified:** %d statistics\n", result.VerifiedCount)
 	output += fmt.Sprintf("**Failed:** %d statistics\n", result.FailedCount)
 	output += fmt.Sprintf("**Total Candidates:** %d\n", result.TotalCandidates)
+	if result.FilteredCount > 0 {
+		output += fmt.Sprintf("**Outside Requested Scope:** %d statistics\n", result.FilteredCount)
+	}
 	output += fmt.Sprintf("**Timestamp:** %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
 
 	if len(result.Statistics) == 0 {
@@ -248,10 +352,47 @@ func formatResponse(result *models.OrchestrationResponse) string {
 	output += "## Verified Statistics\n\n"
 	for i, stat := range result.Statistics {
 		output += fmt.Sprintf("### %d. %s\n\n", i+1, stat.Name)
-		output += fmt.Sprintf("- **Value:** %v %s\n", stat.Value, stat.Unit)
-		output += fmt.Sprintf("- **Source:** %s\n", stat.Source)
-		output += fmt.Sprintf("- **URL:** %s\n", stat.SourceURL)
+		output += fmt.Sprintf("- **Value:** %s\n", stat.DisplayValue())
+		if stat.ValueText != "" {
+			output += fmt.Sprintf("- **As Written:** %s\n", stat.ValueText)
+		}
+		if stat.Period != nil {
+			output += fmt.Sprintf("- **Period:** %s\n", stat.Period)
+		}
+		if stat.Geography != nil {
+			output += fmt.Sprintf("- **Geography:** %s\n", stat.Geography)
+		}
+		if stat.Population != "" {
+			output += fmt.Sprintf("- **Population:** %s\n", stat.Population)
+		}
+		if stat.Methodology != nil {
+			output += fmt.Sprintf("- **Methodology:** %s\n", stat.Methodology)
+		}
+		if stat.SourceTier != "" {
+			output += fmt.Sprintf("- **Source:** %s (%s)\n", stat.Source, stat.SourceTier)
+		} else {
+			output += fmt.Sprintf("- **Source:** %s\n", stat.Source)
+		}
+		if stat.Author != "" {
+			output += fmt.Sprintf("- **Author:** %s\n", stat.Author)
+		}
+		if stat.PublishedDate != "" {
+			output += fmt.Sprintf("- **Published:** %s\n", stat.PublishedDate)
+		}
+		if stat.ModifiedDate != "" {
+			output += fmt.Sprintf("- **Updated:** %s\n", stat.ModifiedDate)
+		}
+		output += fmt.Sprintf("- **URL:** %s\n", stat.CitationURL())
+		if stat.Page > 0 {
+			output += fmt.Sprintf("- **Page:** %d\n", stat.Page)
+		}
 		output += fmt.Sprintf("- **Excerpt:** \"%s\"\n", stat.Excerpt)
+		for _, c := range stat.Corroborating {
+			output += fmt.Sprintf("- **Also Reported By:** %s (%s)\n", c.Source, c.SourceURL)
+		}
+		if len(stat.AgreedModels) > 0 {
+			output += fmt.Sprintf("- **Extracted By:** %s\n", strings.Join(stat.AgreedModels, ", "))
+		}
 		output += fmt.Sprintf("- **Verified:** ✓\n")
 		output += fmt.Sprintf("- **Date Found:** %s\n\n", stat.DateFound.Format("2006-01-02"))
 	}
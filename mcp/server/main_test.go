@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSearchStatisticsSchema checks that the schema advertises every parameter
+// the tool accepts, so clients can discover new options
+func TestSearchStatisticsSchema(t *testing.T) {
+	props, ok := searchStatisticsSchema([]string{"concise", "default"})["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("schema has no properties")
+	}
+
+	params := reflect.TypeOf(SearchStatisticsParams{})
+	for i := 0; i < params.NumField(); i++ {
+		name, _, _ := strings.Cut(params.Field(i).Tag.Get("json"), ",")
+		if _, ok := props[name]; !ok {
+			t.Errorf("schema is missing %q", name)
+		}
+	}
+	if len(props) != params.NumField() {
+		t.Errorf("schema has %d properties, want %d", len(props), params.NumField())
+	}
+
+	variant, _ := props["prompt_variant"].(map[string]interface{})
+	if desc, _ := variant["description"].(string); !strings.Contains(desc, "concise, default") {
+		t.Errorf("prompt_variant description %q does not list the variants", desc)
+	}
+
+	minRelevance, _ := props["min_relevance"].(map[string]interface{})
+	if minRelevance["minimum"] != 0 || minRelevance["maximum"] != 1 {
+		t.Errorf("min_relevance range = [%v, %v], want [0, 1]", minRelevance["minimum"], minRelevance["maximum"])
+	}
+}
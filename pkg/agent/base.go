@@ -1,18 +1,27 @@
 package agent
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/model"
 
 	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/htmltext"
 	"github.com/grokify/stats-agent-team/pkg/llm"
+	"github.com/grokify/stats-agent-team/pkg/pdftext"
 )
 
 // BaseAgent provides common functionality for all agents
@@ -47,34 +56,190 @@ func (ba *BaseAgent) GetProviderInfo() string {
 	return ba.ModelFactory.GetProviderInfo()
 }
 
-// FetchURL fetches content from a URL with proper error handling
-func (ba *BaseAgent) FetchURL(ctx context.Context, url string, maxSizeMB int) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// maxPDFSizeMB is the size limit for PDFs, which run larger than web pages
+const maxPDFSizeMB = 20
+
+// pdfSniffLen is how much of a response is inspected for the PDF header
+const pdfSniffLen = 1024
+
+// Page is a fetched document
+type Page struct {
+	URL         string
+	ContentType string            // Media type reported by the server, or derived from the file extension
+	Body        []byte            // Raw body
+	Title       string            // HTML page title
+	Meta        htmltext.Metadata // Publisher, author and dates declared by an HTML page
+	Text        string            // Readable text: the main content of HTML pages, PDF text with pages separated by form feeds, the decoded body otherwise
+	PDFPages    []pdftext.Page    // Text of each page, for PDFs
+}
+
+// PageOf returns the number of the PDF page containing excerpt, or 0 if the
+// document is not a PDF or no page contains it. PDF text breaks lines wherever
+// the layout does, so whitespace differences are ignored.
+func (p *Page) PageOf(excerpt string) int {
+	want := collapseSpace(excerpt)
+	if want == "" {
+		return 0
+	}
+	for _, pdfPage := range p.PDFPages {
+		if strings.Contains(collapseSpace(pdfPage.Text), want) {
+			return pdfPage.Number
+		}
+	}
+	return 0
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// FetchURL fetches content from a URL with proper error handling.
+// file:// URLs are served from the local search corpus (SEARCH_CORPUS_DIR) only.
+func (ba *BaseAgent) FetchURL(ctx context.Context, targetURL string, maxSizeMB int) (string, error) {
+	body, _, err := ba.fetch(ctx, targetURL, maxSizeMB)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// FetchText fetches a URL and returns its readable text. HTML pages are reduced to
+// their main content, without scripts, styles, navigation and other boilerplate.
+func (ba *BaseAgent) FetchText(ctx context.Context, targetURL string, maxSizeMB int) (string, error) {
+	page, err := ba.FetchPage(ctx, targetURL, maxSizeMB)
+	if err != nil {
+		return "", err
+	}
+	return page.Text, nil
+}
+
+// FetchPage fetches a URL and returns both its raw body and readable text
+func (ba *BaseAgent) FetchPage(ctx context.Context, targetURL string, maxSizeMB int) (*Page, error) {
+	body, contentType, err := ba.fetch(ctx, targetURL, maxSizeMB)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{URL: targetURL, ContentType: contentType, Body: body}
+	if isPDF(body, contentType) {
+		pages, err := pdftext.Extract(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract PDF text: %w", err)
+		}
+		texts := make([]string, len(pages))
+		for i, p := range pages {
+			texts[i] = p.Text
+		}
+		page.PDFPages = pages
+		page.Text = strings.Join(texts, "\f")
+		return page, nil
+	}
+	if !htmltext.IsHTML(body, contentType) {
+		page.Text = htmltext.Decode(body, contentType)
+		return page, nil
+	}
+
+	doc, err := htmltext.Extract(body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	page.Title = doc.Title
+	page.Meta = doc.Meta
+	page.Text = doc.Text
+	return page, nil
+}
+
+// fetch returns the raw body of a URL and its content type
+func (ba *BaseAgent) fetch(ctx context.Context, targetURL string, maxSizeMB int) ([]byte, string, error) {
+	if strings.HasPrefix(targetURL, "file://") {
+		return ba.fetchCorpusFile(targetURL, maxSizeMB)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "StatsAgentTeam/1.0")
 
 	resp, err := ba.Client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, "", fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	// Limit response size
+	// Limit response size. PDFs get a larger limit, recognized by content type,
+	// URL extension or magic bytes since many servers send application/octet-stream.
+	br := bufio.NewReaderSize(resp.Body, pdfSniffLen)
+	head, _ := br.Peek(pdfSniffLen)
+	if isPDF(head, resp.Header.Get("Content-Type")) || strings.EqualFold(path.Ext(resp.Request.URL.Path), ".pdf") {
+		maxSizeMB = max(maxSizeMB, maxPDFSizeMB)
+	}
 	maxBytes := int64(maxSizeMB * 1024 * 1024)
-	limitedReader := io.LimitReader(resp.Body, maxBytes)
+	limitedReader := io.LimitReader(br, maxBytes)
 	body, err := io.ReadAll(limitedReader)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return string(body), nil
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// fetchCorpusFile reads a file:// URL, refusing paths outside the search corpus
+func (ba *BaseAgent) fetchCorpusFile(fileURL string, maxSizeMB int) ([]byte, string, error) {
+	if ba.Cfg.SearchCorpusDir == "" {
+		return nil, "", fmt.Errorf("file URLs require SEARCH_CORPUS_DIR")
+	}
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid file URL: %w", err)
+	}
+
+	root, err := filepath.EvalSymlinks(ba.Cfg.SearchCorpusDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid SEARCH_CORPUS_DIR: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid SEARCH_CORPUS_DIR: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.FromSlash(u.Path))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, "", fmt.Errorf("file %s is outside SEARCH_CORPUS_DIR", path)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".pdf") {
+		maxSizeMB = max(maxSizeMB, maxPDFSizeMB)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	body, err := io.ReadAll(io.LimitReader(f, int64(maxSizeMB*1024*1024)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return body, mime.TypeByExtension(filepath.Ext(path)), nil
+}
+
+// isPDF reports whether a body is a PDF, from its content type or magic bytes.
+// Servers often send PDFs as application/octet-stream or under a wrong type.
+func isPDF(body []byte, contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && (mediaType == "application/pdf" || mediaType == "application/x-pdf") {
+		return true
+	}
+	return pdftext.IsPDF(body)
 }
 
 // LogInfo logs an informational message with agent context
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/stats-agent-team/pkg/config"
+)
+
+func TestFetchSizeLimit(t *testing.T) {
+	const size = 3 * 1024 * 1024 / 2 // Over the 1MB page limit, under the PDF limit
+	pdf := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte{' '}, size)...)
+	html := append([]byte("<html>"), bytes.Repeat([]byte{' '}, size)...)
+
+	mux := http.NewServeMux()
+	serve := func(pattern, contentType string, body []byte) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write(body)
+		})
+	}
+	serve("/typed", "application/pdf", pdf)
+	serve("/sniffed", "application/octet-stream", pdf)
+	serve("/report.pdf", "application/octet-stream", html)
+	serve("/page", "text/html", html)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ba := &BaseAgent{Cfg: &config.Config{}, Client: srv.Client()}
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/typed", len(pdf)},
+		{"/sniffed", len(pdf)},
+		{"/report.pdf", len(html)},
+		{"/page", 1024 * 1024},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			body, _, err := ba.fetch(context.Background(), srv.URL+tt.path, 1)
+			if err != nil {
+				t.Fatalf("fetch() error = %v", err)
+			}
+			if len(body) != tt.want {
+				t.Errorf("fetch() read %d bytes, want %d", len(body), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package canon canonicalizes source URLs and detects duplicate sources, so the
+// same report reached through tracking links, AMP pages or mirrors is only
+// fetched and analyzed once.
+package canon
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// trackingParams are query parameters that identify a click or campaign, not content
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "gbraid": true, "wbraid": true,
+	"msclkid": true, "yclid": true, "igshid": true, "mc_cid": true, "mc_eid": true,
+	"_ga": true, "_gl": true, "_hsenc": true, "_hsmi": true, "mkt_tok": true,
+	"ref_src": true, "oly_anon_id": true, "oly_enc_id": true, "vero_id": true,
+}
+
+// URL returns a cleaned, still fetchable form of a URL: lowercase scheme and host,
+// no default port, fragment or tracking parameters (utm_* and click IDs), and
+// sorted query parameters. URLs that are not absolute http(s) URLs are returned
+// trimmed but otherwise unchanged.
+func URL(raw string) string {
+	u, ok := parseWeb(raw)
+	if !ok {
+		return strings.TrimSpace(raw)
+	}
+	clean(u, false)
+	return u.String()
+}
+
+// Key returns the de-duplication key for a URL: the cleaned URL with https, no
+// "www." and no trailing slash, so http/https and www variants compare equal.
+// AMP variants (Google AMP cache URLs, amp. hosts, /amp and .amp paths, ?amp=1)
+// share the key of the regular page. That can't tell an AMP page from a page
+// that merely has amp in its URL, so keys are only compared, never fetched.
+func Key(raw string) string {
+	u, ok := parseWeb(raw)
+	if !ok {
+		return strings.TrimSpace(raw)
+	}
+	unwrapAMPCache(u)
+	clean(u, true)
+
+	host := strings.TrimPrefix(u.Host, "www.")
+	if rest, ok := strings.CutPrefix(host, "amp."); ok && strings.Contains(rest, ".") {
+		host = rest // amp.example.com, but not amp.dev
+	}
+	u.Path = stripAMPPath(u.Path)
+	u.RawPath = ""
+	key := "https://" + host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// parseWeb parses an absolute http(s) URL
+func parseWeb(raw string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	return u, u.Scheme == "http" || u.Scheme == "https"
+}
+
+// clean lowercases the host and drops the default port, fragment, user info and
+// tracking parameters, sorting the remaining query parameters. With amp, the
+// parameters that request an AMP variant (amp=1, outputType=amp) are dropped too.
+func clean(u *url.URL, amp bool) {
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment, u.RawFragment = "", ""
+	u.User = nil
+
+	query := u.Query()
+	for key, values := range query {
+		lower := strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(lower, "utm_"), trackingParams[lower]:
+			query.Del(key)
+		case amp && lower == "amp" && (len(values) == 0 || values[0] == "" || values[0] == "1" || values[0] == "true"):
+			query.Del(key)
+		case amp && lower == "outputtype" && len(values) > 0 && strings.EqualFold(values[0], "amp"):
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+}
+
+// LinkCanonical returns the URL a page declares with <link rel="canonical">,
+// resolved against pageURL and cleaned with URL, or "" if it declares none
+func LinkCanonical(pageURL, body string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	z := html.NewTokenizer(strings.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				return ""
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return ""
+			case atom.Link:
+				if !hasAttr {
+					continue
+				}
+				var rel, href string
+				for {
+					key, val, more := z.TagAttr()
+					switch string(key) {
+					case "rel":
+						rel = string(val)
+					case "href":
+						href = string(val)
+					}
+					if !more {
+						break
+					}
+				}
+				if href == "" || !hasToken(rel, "canonical") {
+					continue
+				}
+				ref, err := base.Parse(strings.TrimSpace(href))
+				if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") {
+					return ""
+				}
+				return URL(ref.String())
+			}
+		}
+	}
+}
+
+// hasToken reports whether a space-separated attribute value contains token
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrapAMPCache rewrites Google AMP cache and viewer URLs to the publisher URL:
+// https://example-com.cdn.ampproject.org/c/s/example.com/a and
+// https://www.google.com/amp/s/example.com/a both become https://example.com/a
+func unwrapAMPCache(u *url.URL) {
+	host := strings.ToLower(u.Hostname())
+	segments := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+
+	switch {
+	case strings.HasSuffix(host, ".cdn.ampproject.org"):
+		// /c/s/host/path, /v/s/host/path, /i/s/host/path, or without "s" for http
+		if len(segments) < 2 || len(segments[0]) != 1 {
+			return
+		}
+		segments = segments[1:]
+	case (host == "google.com" || strings.HasSuffix(host, ".google.com")) && len(segments) > 1 && segments[0] == "amp":
+		segments = segments[1:]
+	default:
+		return
+	}
+
+	scheme := "http"
+	if segments[0] == "s" {
+		scheme = "https"
+		segments = segments[1:]
+	}
+	if len(segments) == 0 || !strings.Contains(segments[0], ".") {
+		return
+	}
+	u.Scheme = scheme
+	u.Host = segments[0]
+	u.Path = "/" + strings.Join(segments[1:], "/")
+}
+
+// stripAMPPath removes AMP markers from a path: a trailing /amp segment, a leading
+// /amp/ segment and an .amp extension
+func stripAMPPath(path string) string {
+	switch {
+	case path == "/amp" || path == "/amp/":
+		return "/"
+	case strings.HasSuffix(path, "/amp"):
+		return strings.TrimSuffix(path, "amp")
+	case strings.HasSuffix(path, "/amp/"):
+		return strings.TrimSuffix(path, "amp/")
+	case strings.HasPrefix(path, "/amp/"):
+		return strings.TrimPrefix(path, "/amp")
+	case strings.HasSuffix(path, ".amp"):
+		return strings.TrimSuffix(path, ".amp")
+	case strings.HasSuffix(path, ".amp.html"):
+		return strings.TrimSuffix(path, ".amp.html") + ".html"
+	}
+	return path
+}
@@ -0,0 +1,82 @@
+package canon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// ContentHash returns a hash of page content that ignores case and whitespace
+// differences, or "" for empty content
+func ContentHash(content string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Dedup remembers the sources already processed, by canonical URL and content
+// hash. It is safe for concurrent use.
+type Dedup struct {
+	mu     sync.Mutex
+	urls   map[string]string // Canonical key -> first URL recorded for it
+	hashes map[string]string // Content hash -> first URL with that content
+}
+
+// NewDedup creates an empty de-duplicator
+func NewDedup() *Dedup {
+	return &Dedup{
+		urls:   map[string]string{},
+		hashes: map[string]string{},
+	}
+}
+
+// SeenURL reports whether a URL with the same canonical key was recorded before,
+// returning that URL. Otherwise it records rawURL.
+func (d *Dedup) SeenURL(rawURL string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := Key(rawURL)
+	if first, ok := d.urls[key]; ok {
+		return first, true
+	}
+	d.urls[key] = rawURL
+	return "", false
+}
+
+// SeenPage checks a fetched page against the sources recorded so far. The page is
+// a duplicate when the URL it declares canonical (see LinkCanonical; "" if none),
+// or its content, was already recorded for another source; that source's URL is
+// returned. Otherwise the page's URL, canonical URL and content hash are recorded.
+func (d *Dedup) SeenPage(rawURL, canonicalURL, content string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys := []string{Key(rawURL)}
+	if canonicalURL != "" {
+		keys = append(keys, Key(canonicalURL))
+	}
+	for _, key := range keys {
+		if first, ok := d.urls[key]; ok && first != rawURL {
+			return first, true
+		}
+	}
+	hash := ContentHash(content)
+	if first, ok := d.hashes[hash]; ok && hash != "" && first != rawURL {
+		return first, true
+	}
+
+	for _, key := range keys {
+		if _, ok := d.urls[key]; !ok {
+			d.urls[key] = rawURL
+		}
+	}
+	if hash != "" {
+		d.hashes[hash] = rawURL
+	}
+	return "", false
+}
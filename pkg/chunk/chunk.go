@@ -0,0 +1,65 @@
+// Package chunk splits long documents into overlapping pieces that fit an LLM
+// prompt, preferring paragraph, line and sentence boundaries.
+package chunk
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk is a piece of a document
+type Chunk struct {
+	Index  int    // Position of the chunk in the document (0-based)
+	Offset int    // Byte offset of the chunk in the document
+	Text   string // Chunk content
+}
+
+// Split divides text into chunks of at most size bytes, each starting up to
+// overlap bytes before the previous one ended, so a passage cut by one boundary
+// appears whole in one of the chunks. Text that fits in size is one chunk.
+func Split(text string, size, overlap int) []Chunk {
+	if size <= 0 || len(text) <= size {
+		return []Chunk{{Text: text}}
+	}
+	overlap = max(0, min(overlap, size/2))
+
+	var chunks []Chunk
+	start := 0
+	for {
+		end := start + size
+		if end >= len(text) {
+			chunks = append(chunks, Chunk{Index: len(chunks), Offset: start, Text: text[start:]})
+			return chunks
+		}
+		end = boundary(text, start+size/2, end)
+		chunks = append(chunks, Chunk{Index: len(chunks), Offset: start, Text: text[start:end]})
+
+		next := end - overlap
+		if overlap > 0 {
+			// Start the overlap at a word boundary
+			if i := strings.IndexAny(text[next:end], " \n"); i >= 0 {
+				next += i + 1
+			}
+		}
+		start = max(next, start+1)
+		for start < len(text) && !utf8.RuneStart(text[start]) {
+			start++
+		}
+	}
+}
+
+// boundary returns the best place to end a chunk in text[lo:hi]: after the last
+// paragraph break, else the last line break, sentence end or space, else hi
+// (moved back to a rune boundary)
+func boundary(text string, lo, hi int) int {
+	window := text[lo:hi]
+	for _, sep := range []string{"\n\n", "\n", ". ", "? ", "! ", "; ", " "} {
+		if i := strings.LastIndex(window, sep); i >= 0 {
+			return lo + i + len(sep)
+		}
+	}
+	for hi > lo && !utf8.RuneStart(text[hi]) {
+		hi--
+	}
+	return hi
+}
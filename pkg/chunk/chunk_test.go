@@ -0,0 +1,83 @@
+package chunk
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	para := "Electric car sales neared 14 million in 2023. "
+	tests := []struct {
+		name          string
+		text          string
+		size, overlap int
+		wantChunks    int    // 0 to skip the count
+		wantFirst     string // Expected first chunk; empty to skip
+	}{
+		{"fits", "short text", 100, 10, 1, "short text"},
+		{"no size", strings.Repeat(para, 50), 0, 10, 1, ""},
+		{"paragraph boundary", "First paragraph about sales.\n\nSecond paragraph about prices and more.", 40, 0, 2, "First paragraph about sales.\n\n"},
+		{"line boundary", "First line about sales\nSecond line about prices and more text", 30, 0, 3, "First line about sales\n"},
+		{"sentence boundary", "Sales rose. Prices fell by a lot this year", 20, 0, 3, "Sales rose. "},
+		{"no boundary", strings.Repeat("x", 25), 10, 0, 3, "xxxxxxxxxx"},
+		{"overlap", strings.Repeat(para, 20), 200, 50, 0, ""},
+		{"overlap capped at half", strings.Repeat(para, 20), 100, 500, 0, ""},
+		{"multibyte runes", strings.Repeat("é", 30) + " " + strings.Repeat("€", 30), 25, 5, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(tt.text, tt.size, tt.overlap)
+			if tt.wantChunks > 0 && len(chunks) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d: %q", len(chunks), tt.wantChunks, chunks)
+			}
+			if tt.wantFirst != "" && chunks[0].Text != tt.wantFirst {
+				t.Errorf("first chunk = %q, want %q", chunks[0].Text, tt.wantFirst)
+			}
+
+			covered := 0
+			for i, c := range chunks {
+				if c.Index != i {
+					t.Errorf("chunk %d has index %d", i, c.Index)
+				}
+				if tt.size > 0 && len(c.Text) > tt.size {
+					t.Errorf("chunk %d has %d bytes, more than %d", i, len(c.Text), tt.size)
+				}
+				if tt.text[c.Offset:c.Offset+len(c.Text)] != c.Text {
+					t.Errorf("chunk %d is not the text at offset %d", i, c.Offset)
+				}
+				if !utf8.ValidString(c.Text) {
+					t.Errorf("chunk %d splits a rune: %q", i, c.Text)
+				}
+				if c.Offset > covered {
+					t.Errorf("chunk %d starts at %d, leaving a gap after %d", i, c.Offset, covered)
+				}
+				if i > 0 && tt.overlap == 0 && c.Offset != covered {
+					t.Errorf("chunk %d starts at %d without overlap, want %d", i, c.Offset, covered)
+				}
+				covered = c.Offset + len(c.Text)
+			}
+			if covered != len(tt.text) {
+				t.Errorf("chunks end at %d of %d bytes", covered, len(tt.text))
+			}
+		})
+	}
+}
+
+func TestSplitOverlapRepeatsPassage(t *testing.T) {
+	text := strings.Repeat("Sales rose 35% in 2023 across Europe. ", 20)
+	chunks := Split(text, 200, 80)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks", len(chunks))
+	}
+	for i := 1; i < len(chunks); i++ {
+		prev := chunks[i-1]
+		if chunks[i].Offset >= prev.Offset+len(prev.Text) {
+			t.Errorf("chunk %d does not overlap chunk %d", i, i-1)
+		}
+		// The overlap starts at a word
+		if c := text[chunks[i].Offset-1]; c != ' ' && c != '\n' {
+			t.Errorf("chunk %d starts mid-word: %q", i, chunks[i].Text[:10])
+		}
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 )
 
 // Config holds the application configuration
@@ -12,6 +13,9 @@ type Config struct {
 	LLMModel    string
 	LLMBaseURL  string // For Ollama or custom endpoints
 
+	LLMContextTokens int // Model context window in tokens (0 = known size for LLMModel)
+	LLMMaxRepairs    int // Re-prompts after a response that doesn't match the expected JSON schema
+
 	// Provider-specific API keys
 	GeminiAPIKey string
 	ClaudeAPIKey string
@@ -20,11 +24,45 @@ type Config struct {
 	OllamaURL    string
 
 	// Search Configuration
-	SearchProvider string // "serper", "serpapi"
-	SerperAPIKey   string
-	SerpAPIKey     string
+	SearchProvider      string // "serper", "serpapi", "searxng", "fixture", "corpus", or an ordered comma-separated list
+	SearchMode          string // "failover" or "fanout" when SearchProvider lists several providers
+	SerperAPIKey        string
+	SerpAPIKey          string
+	SearxNGURL          string // Base URL of a SearxNG instance (e.g., "http://localhost:8888")
+	SearxNGEngines      string // Comma-separated SearxNG engines (instance defaults if empty)
+	SearxNGCategories   string // Comma-separated SearxNG categories (instance defaults if empty)
+	SearchMaxQueries    int    // Query variants run per research request (1 disables expansion)
+	SearchMaxPages      int    // Result pages fetched per query when more sources are needed
+	SearchQueryLLM      bool   // Ask the LLM for additional query variants
+	SearchFixtureDir    string // Recorded search results replayed by the fixture provider
+	SearchFixtureRecord bool   // Record live search results into SearchFixtureDir
+	SearchCorpusDir     string // Local HTML/Markdown/PDF documents searched by the corpus provider
+
+	// Synthesis Configuration
+	SynthesisMaxChunks        int    // Chunks of a long document sent for extraction at most
+	SynthesisChunkConcurrency int    // Chunks of one document extracted at once
+	SynthesisWorkers          int    // Sources fetched and extracted at once
+	SynthesisPerHost          int    // Concurrent fetches per host (0 = unlimited)
+	SynthesisTimeout          int    // Seconds a synthesis request may take (0 = no deadline)
+	SynthesisScanMode         string // LLM statistics the number scanner can't confirm: discard, flag or off
+
+	SynthesisEnsemble          string // Comma-separated provider:model list that each extract the page text (off if empty)
+	SynthesisEnsembleAgreement int    // Ensemble models that must extract a statistic to keep it (0 = majority)
+
+	// Relevance Configuration
+	RelevanceScorer         string // "llm", "embedding" or "off"
+	RelevanceEmbeddingURL   string // Ollama-compatible endpoint for the embedding scorer
+	RelevanceEmbeddingModel string
+
+	// Prompt Configuration
+	PromptsDir    string // Directory of <id>/<variant>.tmpl prompt overrides (embedded prompts if empty)
+	PromptVariant string // Prompt variant used when a request names none ("default" if empty)
+
+	// Source Reputation Configuration
+	ReputationFile string // YAML/JSON domain reputation registry (embedded default if empty)
 
 	// Agent Configuration
+	ResearchLegacyAPI    bool // Serve the deprecated /research placeholder-candidate response
 	ResearchAgentURL     string
 	SynthesisAgentURL    string
 	VerificationAgentURL string
@@ -55,6 +93,9 @@ func LoadConfig() *Config {
 		LLMModel:    getEnv("LLM_MODEL", getDefaultModel(provider)),
 		LLMBaseURL:  getEnv("LLM_BASE_URL", ""),
 
+		LLMContextTokens: getEnvInt("LLM_CONTEXT_TOKENS", 0),
+		LLMMaxRepairs:    getEnvInt("LLM_MAX_REPAIRS", 2),
+
 		// Provider-specific API keys
 		GeminiAPIKey: getEnv("GEMINI_API_KEY", getEnv("GOOGLE_API_KEY", "")),
 		ClaudeAPIKey: getEnv("CLAUDE_API_KEY", getEnv("ANTHROPIC_API_KEY", "")),
@@ -64,10 +105,47 @@ func LoadConfig() *Config {
 
 		// Search settings
 		SearchProvider: getEnv("SEARCH_PROVIDER", "serper"),
+		SearchMode:     getEnv("SEARCH_MODE", "failover"),
 		SerperAPIKey:   getEnv("SERPER_API_KEY", ""),
 		SerpAPIKey:     getEnv("SERPAPI_API_KEY", ""),
 
+		SearchMaxQueries: getEnvInt("SEARCH_MAX_QUERIES", 4),
+		SearchMaxPages:   getEnvInt("SEARCH_MAX_PAGES", 3),
+		SearchQueryLLM:   getEnv("SEARCH_QUERY_LLM", "false") == "true",
+
+		SearxNGURL:        getEnv("SEARXNG_URL", ""),
+		SearxNGEngines:    getEnv("SEARXNG_ENGINES", ""),
+		SearxNGCategories: getEnv("SEARXNG_CATEGORIES", "general"),
+
+		SearchFixtureDir:    getEnv("SEARCH_FIXTURE_DIR", ""),
+		SearchFixtureRecord: getEnv("SEARCH_FIXTURE_RECORD", "false") == "true",
+		SearchCorpusDir:     getEnv("SEARCH_CORPUS_DIR", ""),
+
+		// Synthesis
+		SynthesisMaxChunks:        getEnvInt("SYNTHESIS_MAX_CHUNKS", 10),
+		SynthesisChunkConcurrency: getEnvInt("SYNTHESIS_CHUNK_CONCURRENCY", 3),
+		SynthesisWorkers:          getEnvInt("SYNTHESIS_WORKERS", 4),
+		SynthesisPerHost:          getEnvInt("SYNTHESIS_PER_HOST", 2),
+		SynthesisTimeout:          getEnvInt("SYNTHESIS_TIMEOUT", 50),
+		SynthesisScanMode:         getEnv("SYNTHESIS_SCAN_MODE", "discard"),
+
+		SynthesisEnsemble:          getEnv("SYNTHESIS_ENSEMBLE", ""),
+		SynthesisEnsembleAgreement: getEnvInt("SYNTHESIS_ENSEMBLE_AGREEMENT", 0),
+
+		// Relevance
+		RelevanceScorer:         getEnv("RELEVANCE_SCORER", "llm"),
+		RelevanceEmbeddingURL:   getEnv("RELEVANCE_EMBEDDING_URL", getEnv("OLLAMA_URL", "http://localhost:11434")),
+		RelevanceEmbeddingModel: getEnv("RELEVANCE_EMBEDDING_MODEL", "nomic-embed-text"),
+
+		// Prompts
+		PromptsDir:    getEnv("PROMPTS_DIR", ""),
+		PromptVariant: getEnv("PROMPT_VARIANT", ""),
+
+		// Source reputation
+		ReputationFile: getEnv("REPUTATION_FILE", ""),
+
 		// Agent URLs
+		ResearchLegacyAPI:    getEnv("RESEARCH_LEGACY_API", "false") == "true",
 		ResearchAgentURL:     getEnv("RESEARCH_AGENT_URL", "http://localhost:8001"),
 		SynthesisAgentURL:    getEnv("SYNTHESIS_AGENT_URL", "http://localhost:8004"),
 		VerificationAgentURL: getEnv("VERIFICATION_AGENT_URL", "http://localhost:8002"),
@@ -134,3 +212,11 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt gets an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -11,17 +11,18 @@ import (
 	"time"
 
 	"google.golang.org/adk/model"
-	"google.golang.org/genai"
 
 	"github.com/grokify/stats-agent-team/pkg/config"
 	"github.com/grokify/stats-agent-team/pkg/llm"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
 )
 
 // LLMSearchService provides direct LLM-based statistics search (like ChatGPT)
 type LLMSearchService struct {
-	cfg   *config.Config
-	model model.LLM
+	cfg     *config.Config
+	model   model.LLM
+	prompts *prompts.Registry
 }
 
 // NewLLMSearchService creates a new direct LLM search service
@@ -35,9 +36,15 @@ func NewLLMSearchService(cfg *config.Config) (*LLMSearchService, error) {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	promptRegistry, err := prompts.LoadFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+
 	return &LLMSearchService{
-		cfg:   cfg,
-		model: llmModel,
+		cfg:     cfg,
+		model:   llmModel,
+		prompts: promptRegistry,
 	}, nil
 }
 
@@ -49,96 +56,53 @@ func (s *LLMSearchService) SearchStatistics(ctx context.Context, topic string, m
 
 // SearchStatisticsWithVerification allows optional verification agent integration
 func (s *LLMSearchService) SearchStatisticsWithVerification(ctx context.Context, topic string, minStats int, verifyWithAgent bool) (*models.OrchestrationResponse, error) {
-	prompt := fmt.Sprintf(`Find %d or more verified, numerical statistics about "%s".
-
-For each statistic, provide:
-1. name: Brief description
-2. value: The exact numerical value (as a plain number, NO commas or formatting)
-3. unit: Unit of measurement
-4. source: Name of the authoritative source
-5. source_url: Direct URL to the source (if available)
-6. excerpt: Exact quote containing the statistic
-
-IMPORTANT INSTRUCTIONS:
-- Prioritize statistics from reputable sources (government agencies, research organizations, academic institutions)
-- Include the actual URL where each statistic can be verified
-- Use real, verifiable data - do not make up statistics
-- Extract the exact numerical values
-- Provide verbatim excerpts
-- CRITICAL: The "value" field must be a plain number with NO commas (e.g., 2537 not 2,537)
-
-Return a JSON array:
-[
-  {
-    "name": "Global temperature increase since 1880",
-    "value": 1.1,
-    "unit": "degrees Celsius",
-    "source": "NASA",
-    "source_url": "https://climate.nasa.gov/vital-signs/global-temperature/",
-    "excerpt": "The planet's average surface temperature has risen about 1.1 degrees Celsius since the late 19th century"
-  },
-  {
-    "name": "Example large number",
-    "value": 75000,
-    "unit": "people",
-    "source": "Example",
-    "source_url": "https://example.com",
-    "excerpt": "Over 75,000 people participated"
-  }
-]
-
-REMEMBER: Numbers like 75,000 should be written as 75000 (no comma).
-
-Find at least %d statistics. Return only the JSON array, no other text.`, minStats, topic, minStats)
-
-	// Call LLM
-	req := &model.LLMRequest{
-		Contents: genai.Text(prompt),
-	}
-
-	var response string
-	for llmResp, err := range s.model.GenerateContent(ctx, req, false) {
-		if err != nil {
-			return nil, fmt.Errorf("LLM generation failed: %w", err)
-		}
-		if llmResp.Content != nil && llmResp.Content.Parts != nil {
-			for _, part := range llmResp.Content.Parts {
-				if part.Text != "" {
-					response += part.Text
-				}
-			}
-		}
+	prompt, used, err := s.prompts.Render(prompts.DirectSearch, s.prompts.Preferred(prompts.DirectSearch, s.cfg.PromptVariant), struct {
+		Topic    string
+		MinStats int
+	}{topic, minStats})
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract JSON from response
-	response = extractJSONFromMarkdown(response)
-
-	// Parse JSON
+	// The response schema: source_url may be missing, everything else is required
 	type StatResponse struct {
 		Name      string  `json:"name"`
-		Value     float32 `json:"value"`
+		Value     float64 `json:"value"`
+		ValueText string  `json:"value_text"`
 		Unit      string  `json:"unit"`
 		Source    string  `json:"source"`
-		SourceURL string  `json:"source_url"`
+		SourceURL string  `json:"source_url,omitempty"`
 		Excerpt   string  `json:"excerpt"`
 	}
 
-	var stats []StatResponse
-	if err := json.Unmarshal([]byte(response), &stats); err != nil {
-		return nil, fmt.Errorf("failed to parse LLM response: %w\nResponse: %s", err, response)
+	// Call LLM, re-prompting if the JSON doesn't match the schema
+	stats, err := llm.GenerateJSON[[]StatResponse](ctx, s.model, prompt, llm.JSONOptions{MaxRepairs: s.cfg.LLMMaxRepairs, Prompts: s.prompts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statistics from LLM: %w", err)
 	}
 
 	// Convert to candidate statistics for potential verification
 	candidates := make([]models.CandidateStatistic, 0, len(stats))
 	for _, stat := range stats {
+		// The literal value text must be quoted in the excerpt
+		if stat.ValueText != "" && !strings.Contains(stat.Excerpt, stat.ValueText) {
+			log.Printf("[Direct] Skipping %q - value text %q not in excerpt", stat.Name, stat.ValueText)
+			continue
+		}
+
 		candidates = append(candidates, models.CandidateStatistic{
-			Name:      stat.Name,
-			Value:     stat.Value,
-			Unit:      stat.Unit,
-			Source:    stat.Source,
-			SourceURL: stat.SourceURL,
-			Excerpt:   stat.Excerpt,
+			Name:          stat.Name,
+			Value:         stat.Value,
+			ValueText:     stat.ValueText,
+			Unit:          stat.Unit,
+			Source:        stat.Source,
+			SourceURL:     stat.SourceURL,
+			Excerpt:       stat.Excerpt,
+			PromptID:      used.ID,
+			PromptVariant: used.Variant,
+			PromptVersion: used.Version,
 		})
+		candidates[len(candidates)-1].NormalizeValue()
 	}
 
 	// If verification requested, send to verification agent
@@ -149,16 +113,8 @@ Find at least %d statistics. Return only the JSON array, no other text.`, minSta
 	// Otherwise, trust LLM claims and mark as verified
 	verifiedStats := make([]models.Statistic, 0, len(candidates))
 	for _, cand := range candidates {
-		verifiedStats = append(verifiedStats, models.Statistic{
-			Name:      cand.Name,
-			Value:     cand.Value,
-			Unit:      cand.Unit,
-			Source:    cand.Source,
-			SourceURL: cand.SourceURL,
-			Excerpt:   cand.Excerpt,
-			Verified:  true, // Marked as verified since from LLM with sources (not web-verified)
-			DateFound: time.Now(),
-		})
+		// Marked as verified since from LLM with sources (not web-verified)
+		verifiedStats = append(verifiedStats, *cand.ToStatistic(true))
 	}
 
 	return &models.OrchestrationResponse{
@@ -235,21 +191,3 @@ func (s *LLMSearchService) verifyWithVerificationAgent(ctx context.Context, topi
 		TargetCount:     minStats,
 	}, nil
 }
-
-// extractJSONFromMarkdown removes markdown code fences from response
-func extractJSONFromMarkdown(response string) string {
-	response = strings.TrimSpace(response)
-
-	// Try to find JSON array
-	startIdx := strings.Index(response, "[")
-	if startIdx == -1 {
-		return response
-	}
-
-	endIdx := strings.LastIndex(response, "]")
-	if endIdx == -1 || endIdx < startIdx {
-		return response
-	}
-
-	return strings.TrimSpace(response[startIdx : endIdx+1])
-}
@@ -0,0 +1,233 @@
+// Package htmltext extracts the readable main content of HTML pages as plain
+// text, so LLM prompts and excerpt checks see the article rather than scripts,
+// styles and navigation.
+package htmltext
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Document is the readable content of an HTML page
+type Document struct {
+	Title string   // Page title from <title>
+	Text  string   // Main content, with headings, list items and table rows on their own lines
+	Meta  Metadata // Publisher, author and dates the page declares
+}
+
+// IsHTML reports whether a body is HTML, from its content type or, when the
+// content type is missing or generic, by sniffing the body
+func IsHTML(body []byte, contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	}
+	return strings.HasPrefix(http.DetectContentType(body), "text/html")
+}
+
+// Decode converts a body to UTF-8 using the charset named in the content type,
+// a byte order mark or a <meta charset> declaration, assuming UTF-8 otherwise
+func Decode(body []byte, contentType string) string {
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return string(body)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return string(body)
+	}
+	return string(decoded)
+}
+
+// Extract returns the title, publication metadata and main-content text of an
+// HTML page. Scripts, styles, forms, navigation, sidebars and similar
+// boilerplate are dropped; when the page marks its main content (<main>,
+// <article>, role="main"), only that is kept. Entities are decoded and
+// whitespace is collapsed.
+func Extract(body []byte, contentType string) (*Document, error) {
+	root, err := html.Parse(strings.NewReader(Decode(body, contentType)))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	if title := findFirst(root, atom.Title); title != nil {
+		doc.Title = strings.Join(strings.Fields(nodeText(title)), " ")
+	}
+	doc.Meta = readMetadata(root)
+
+	content := findFirst(root, atom.Body)
+	if content == nil {
+		content = root
+	}
+	prune(content, false)
+	content = mainContent(content)
+
+	w := &textWriter{}
+	w.render(content)
+	doc.Text = w.String()
+	return doc, nil
+}
+
+// Text returns the main-content text of an HTML page (see Extract)
+func Text(body []byte, contentType string) (string, error) {
+	doc, err := Extract(body, contentType)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text, nil
+}
+
+// droppedElements never hold article content
+var droppedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Math: true, atom.Iframe: true,
+	atom.Object: true, atom.Embed: true, atom.Canvas: true, atom.Video: true,
+	atom.Audio: true, atom.Nav: true, atom.Aside: true, atom.Form: true,
+	atom.Button: true, atom.Select: true, atom.Textarea: true, atom.Input: true,
+	atom.Dialog: true, atom.Menu: true, atom.Link: true, atom.Meta: true,
+}
+
+// boilerplateRoles are ARIA roles of page furniture
+var boilerplateRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"search": true, "dialog": true, "alertdialog": true, "menu": true, "menubar": true,
+}
+
+// boilerplateName matches class and id values of page furniture
+var boilerplateName = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|navigation|menu|breadcrumbs?|sidebar|footer|masthead|cookies?|consent|gdpr|banner|advert|advertisement|ads?|sponsored|promo|newsletter|subscribe|signup|social|share|sharing|related|recommended|comments?|popup|modal|skip-link|print-only)([\s_-]|$)`)
+
+// prune removes boilerplate elements below n. Page-level <header> and <footer>
+// are dropped, but those inside an article or <main> are kept (bylines, sources).
+func prune(n *html.Node, inContent bool) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode {
+			n.RemoveChild(c)
+		} else if c.Type == html.ElementNode {
+			if isBoilerplate(c, inContent) {
+				n.RemoveChild(c)
+			} else {
+				prune(c, inContent || c.DataAtom == atom.Article || c.DataAtom == atom.Main || attr(c, "role") == "main")
+			}
+		}
+		c = next
+	}
+}
+
+// isBoilerplate reports whether an element is page furniture rather than content
+func isBoilerplate(n *html.Node, inContent bool) bool {
+	if droppedElements[n.DataAtom] {
+		return true
+	}
+	if (n.DataAtom == atom.Header || n.DataAtom == atom.Footer) && !inContent {
+		return true
+	}
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", ""); strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	if boilerplateRoles[attr(n, "role")] {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Body, atom.Main, atom.Article, atom.Table, atom.Thead, atom.Tbody, atom.Tr, atom.Td, atom.Th:
+		return false
+	}
+	return boilerplateName.MatchString(attr(n, "class")) || boilerplateName.MatchString(attr(n, "id"))
+}
+
+// mainContent returns the element the page marks as its main content, if it holds
+// a substantial share of the page's text; otherwise it returns body
+func mainContent(body *html.Node) *html.Node {
+	var best *html.Node
+	bestLen := 0
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom == atom.Main || c.DataAtom == atom.Article || attr(c, "role") == "main" || attr(c, "itemprop") == "articleBody" {
+				if l := contentLength(c); l > bestLen {
+					best, bestLen = c, l
+				}
+			}
+			visit(c)
+		}
+	}
+	visit(body)
+
+	// Listing pages wrap each teaser in <article>; keep the whole page then
+	if best == nil || bestLen*4 < contentLength(body) {
+		return body
+	}
+	return best
+}
+
+// contentLength is the length of the non-link text below n
+func contentLength(n *html.Node) int {
+	switch {
+	case n.Type == html.TextNode:
+		return len(strings.TrimSpace(n.Data))
+	case n.Type == html.ElementNode && n.DataAtom == atom.A:
+		return 0
+	}
+	total := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		total += contentLength(c)
+	}
+	return total
+}
+
+// findFirst returns the first element of the given type in document order
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findFirst(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText concatenates the text below n
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
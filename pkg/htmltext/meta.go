@@ -0,0 +1,346 @@
+package htmltext
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Metadata is what a page declares about its publication
+type Metadata struct {
+	Publisher string // Publishing organization or site name
+	Author    string // Author names, joined with "; "
+	Published string // Publication date (YYYY-MM-DD)
+	Modified  string // Last-modified date (YYYY-MM-DD)
+}
+
+// readMetadata collects publication metadata from, in order of preference,
+// JSON-LD (schema.org Article, Dataset, Report, WebPage), <meta> tags (Open
+// Graph, article:*, citation_*, Dublin Core and common name= variants),
+// microdata itemprops and visible bylines and <time> elements. Each field
+// takes the first value found.
+func readMetadata(root *html.Node) Metadata {
+	var md Metadata
+	var metas []*html.Node
+	var scripts []*html.Node
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.DataAtom == atom.Meta:
+				metas = append(metas, n)
+			case n.DataAtom == atom.Script && strings.EqualFold(attr(n, "type"), "application/ld+json"):
+				scripts = append(scripts, n)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(root)
+
+	for _, s := range scripts {
+		readJSONLD(nodeText(s), &md)
+	}
+	readMetaTags(metas, &md)
+	readVisible(root, &md)
+	return md
+}
+
+// jsonLDTypes are the schema.org types whose dates and authors describe the page
+var jsonLDTypes = regexp.MustCompile(`^(\w*Article|BlogPosting|Report|Dataset|WebPage|CreativeWork|ScholarlyArticle|DataCatalog|Book|Thesis)$`)
+
+// readJSONLD fills md from a JSON-LD block: a node, an array of nodes or a @graph
+func readJSONLD(data string, md *Metadata) {
+	var v any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &v); err != nil {
+		return
+	}
+	var nodes []map[string]any
+	var collect func(any)
+	collect = func(v any) {
+		switch t := v.(type) {
+		case []any:
+			for _, item := range t {
+				collect(item)
+			}
+		case map[string]any:
+			if graph, ok := t["@graph"]; ok {
+				collect(graph)
+			}
+			nodes = append(nodes, t)
+		}
+	}
+	collect(v)
+
+	for _, n := range nodes {
+		if !hasJSONLDType(n["@type"]) {
+			continue
+		}
+		setIfEmpty(&md.Publisher, jsonLDName(n["publisher"]))
+		if md.Publisher == "" && n["@type"] == "Dataset" {
+			setIfEmpty(&md.Publisher, jsonLDName(n["creator"]))
+		}
+		setIfEmpty(&md.Author, jsonLDName(n["author"]))
+		setIfEmpty(&md.Published, normalizeDate(jsonLDString(n["datePublished"])))
+		setIfEmpty(&md.Published, normalizeDate(jsonLDString(n["dateCreated"])))
+		setIfEmpty(&md.Modified, normalizeDate(jsonLDString(n["dateModified"])))
+	}
+}
+
+// hasJSONLDType reports whether a @type (a string or list) names a page type
+func hasJSONLDType(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return jsonLDTypes.MatchString(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && jsonLDTypes.MatchString(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonLDName returns the names of a Person/Organization value: a string, an
+// object with "name", or a list of either
+func jsonLDName(v any) string {
+	switch t := v.(type) {
+	case string:
+		if isURL(t) {
+			return ""
+		}
+		return cleanName(t)
+	case map[string]any:
+		return jsonLDName(t["name"])
+	case []any:
+		var names []string
+		for _, item := range t {
+			if name := jsonLDName(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, "; ")
+	}
+	return ""
+}
+
+func jsonLDString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// metaKeys are the <meta> name/property keys of each field, in order of preference
+var metaKeys = struct {
+	publisher, author, published, modified []string
+}{
+	publisher: []string{"og:site_name", "citation_publisher", "dc.publisher", "dcterms.publisher", "publisher", "application-name"},
+	author:    []string{"citation_author", "author", "article:author", "dc.creator", "dcterms.creator", "parsely-author", "sailthru.author"},
+	published: []string{"article:published_time", "citation_publication_date", "citation_date", "dc.date.issued", "dcterms.issued", "dc.date", "dcterms.date", "date", "pubdate", "publish-date", "parsely-pub-date", "sailthru.date"},
+	modified:  []string{"article:modified_time", "og:updated_time", "dcterms.modified", "dc.date.modified", "last-modified"},
+}
+
+// readMetaTags fills md from <meta name=... content=...> and <meta property=...>
+// tags. Repeated author tags (citation_author) are joined.
+func readMetaTags(metas []*html.Node, md *Metadata) {
+	values := map[string][]string{}
+	for _, m := range metas {
+		key := strings.ToLower(attr(m, "property"))
+		if key == "" {
+			key = strings.ToLower(attr(m, "name"))
+		}
+		if key == "" {
+			key = strings.ToLower(attr(m, "itemprop"))
+		}
+		content := strings.TrimSpace(attr(m, "content"))
+		if key != "" && content != "" {
+			values[key] = append(values[key], content)
+		}
+	}
+
+	for _, key := range metaKeys.publisher {
+		if len(values[key]) > 0 && !isURL(values[key][0]) {
+			setIfEmpty(&md.Publisher, cleanName(values[key][0]))
+		}
+	}
+	for _, key := range metaKeys.author {
+		var names []string
+		for _, v := range values[key] {
+			if !isURL(v) {
+				names = append(names, cleanName(v))
+			}
+		}
+		setIfEmpty(&md.Author, strings.Join(names, "; "))
+	}
+	for _, key := range metaKeys.published {
+		if len(values[key]) > 0 {
+			setIfEmpty(&md.Published, normalizeDate(values[key][0]))
+		}
+	}
+	for _, key := range metaKeys.modified {
+		if len(values[key]) > 0 {
+			setIfEmpty(&md.Modified, normalizeDate(values[key][0]))
+		}
+	}
+	if len(values["datepublished"]) > 0 {
+		setIfEmpty(&md.Published, normalizeDate(values["datepublished"][0]))
+	}
+	if len(values["datemodified"]) > 0 {
+		setIfEmpty(&md.Modified, normalizeDate(values["datemodified"][0]))
+	}
+}
+
+// bylineName matches class and id values of byline elements
+var bylineName = regexp.MustCompile(`(?i)(^|[\s_-])(byline|author|authors|author-name|contributor)([\s_-]|$)`)
+
+// bylinePrefix strips the "By" or "Written by" lead-in of a byline
+var bylinePrefix = regexp.MustCompile(`(?i)^(written\s+)?by[:\s]+`)
+
+// maxBylineLength caps visible bylines, which class matching can mistake for
+// whole author bio boxes
+const maxBylineLength = 100
+
+// readVisible fills md from microdata (itemprop="author", "datePublished",
+// "dateModified"), rel="author" links, byline elements and the first <time>
+// element with a datetime
+func readVisible(root *html.Node, md *Metadata) {
+	var firstTime string
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				visit(c)
+			}
+			return
+		}
+		if n.DataAtom == atom.Script || n.DataAtom == atom.Style {
+			return
+		}
+
+		itemprop := strings.ToLower(attr(n, "itemprop"))
+		switch {
+		case itemprop == "datepublished":
+			setIfEmpty(&md.Published, normalizeDate(firstAttr(n, "datetime", "content")))
+		case itemprop == "datemodified":
+			setIfEmpty(&md.Modified, normalizeDate(firstAttr(n, "datetime", "content")))
+		case itemprop == "author" || strings.EqualFold(attr(n, "rel"), "author") ||
+			bylineName.MatchString(attr(n, "class")) || bylineName.MatchString(attr(n, "id")):
+			if md.Author == "" {
+				if name := byline(n); name != "" {
+					md.Author = name
+					return
+				}
+			}
+		}
+		if n.DataAtom == atom.Time && firstTime == "" {
+			firstTime = normalizeDate(attr(n, "datetime"))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(root)
+	setIfEmpty(&md.Published, firstTime)
+}
+
+// byline returns the author name of a byline element: an itemprop="name" child
+// if present, else its text without the "By" lead-in
+func byline(n *html.Node) string {
+	var name *html.Node
+	var find func(*html.Node)
+	find = func(c *html.Node) {
+		for ; c != nil && name == nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && strings.EqualFold(attr(c, "itemprop"), "name") {
+				name = c
+				return
+			}
+			find(c.FirstChild)
+		}
+	}
+	find(n.FirstChild)
+	text := ""
+	if name != nil {
+		text = firstAttr(name, "content")
+		if text == "" {
+			text = nodeText(name)
+		}
+	} else {
+		text = nodeText(n)
+	}
+	text = bylinePrefix.ReplaceAllString(strings.Join(strings.Fields(text), " "), "")
+	if len(text) > maxBylineLength || !strings.ContainsFunc(text, unicode.IsLetter) {
+		return ""
+	}
+	return cleanName(text)
+}
+
+// dateLayouts are the date formats pages use in metadata
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"20060102",
+	time.RFC1123,
+	time.RFC1123Z,
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Monday, January 2, 2006",
+}
+
+// normalizeDate converts a metadata date to YYYY-MM-DD, or returns "" when it
+// cannot be parsed
+func normalizeDate(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	// Dates with a time in an unlisted format ("2024-03-05T10:00:00.000+0100")
+	if len(raw) > 10 && (raw[10] == 'T' || raw[10] == ' ') {
+		if t, err := time.Parse("2006-01-02", raw[:10]); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+func firstAttr(n *html.Node, keys ...string) string {
+	for _, key := range keys {
+		if v := attr(n, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func setIfEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+func cleanName(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
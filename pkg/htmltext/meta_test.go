@@ -0,0 +1,72 @@
+package htmltext
+
+import "testing"
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"  2024-03-05  ", "2024-03-05"},
+		{"2024-03-05T10:00:00Z", "2024-03-05"},
+		{"2024-03-05T23:30:00-05:00", "2024-03-05"},
+		{"2024-03-05T10:00:00+0100", "2024-03-05"},
+		{"2024-03-05T10:00:00", "2024-03-05"},
+		{"2024-03-05T10:00", "2024-03-05"},
+		{"2024-03-05 10:00:00", "2024-03-05"},
+		{"2024-03-05T10:00:00.000+0100", "2024-03-05"},
+		{"2024/03/05", "2024-03-05"},
+		{"20240305", "2024-03-05"},
+		{"Tue, 05 Mar 2024 10:00:00 GMT", "2024-03-05"},
+		{"Tue, 05 Mar 2024 10:00:00 +0000", "2024-03-05"},
+		{"Mar 5, 2024", "2024-03-05"},
+		{"March 5, 2024", "2024-03-05"},
+		{"5 Mar 2024", "2024-03-05"},
+		{"5 March 2024", "2024-03-05"},
+		{"Tuesday, March 5, 2024", "2024-03-05"},
+		{"2024-13-05", ""},
+		{"spring 2024", ""},
+		{"2024", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := normalizeDate(tt.raw); got != tt.want {
+				t.Errorf("normalizeDate(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		body string
+		want Metadata
+	}{
+		{"json-ld", `<script type="application/ld+json">{"@type": "NewsArticle", "datePublished": "2024-03-05T10:00:00Z",
+			"dateModified": "2024-03-07", "author": [{"@type": "Person", "name": "Ana Ruiz"}, {"name": "Li Wei"}],
+			"publisher": {"@type": "Organization", "name": "IEA"}}</script>`, "",
+			Metadata{Publisher: "IEA", Author: "Ana Ruiz; Li Wei", Published: "2024-03-05", Modified: "2024-03-07"}},
+		{"meta tags", `<meta property="og:site_name" content="Pew Research Center">
+			<meta name="author" content="Jane Doe"><meta property="article:published_time" content="2023-11-02T09:00:00-04:00">`, "",
+			Metadata{Publisher: "Pew Research Center", Author: "Jane Doe", Published: "2023-11-02"}},
+		{"json-ld wins over meta", `<meta property="article:published_time" content="2020-01-01">
+			<script type="application/ld+json">{"@graph": [{"@type": "WebPage", "datePublished": "2024-01-15"}]}</script>`, "",
+			Metadata{Published: "2024-01-15"}},
+		{"visible byline and time", "", `<article><p class="byline">By Sam Lee</p><time datetime="2022-06-30">June 30</time><p>Text</p></article>`,
+			Metadata{Author: "Sam Lee", Published: "2022-06-30"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Extract([]byte("<html><head>"+tt.head+"</head><body>"+tt.body+"</body></html>"), "text/html")
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if doc.Meta != tt.want {
+				t.Errorf("Meta = %+v, want %+v", doc.Meta, tt.want)
+			}
+		})
+	}
+}
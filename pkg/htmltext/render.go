@@ -0,0 +1,219 @@
+package htmltext
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockElements start on a new line
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Center: true,
+	atom.Details: true, atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Fieldset: true,
+	atom.Figcaption: true, atom.Figure: true, atom.Footer: true, atom.Header: true,
+	atom.Main: true, atom.Section: true, atom.Summary: true, atom.Caption: true,
+}
+
+// textWriter renders a DOM subtree as plain text. Whitespace is collapsed as a
+// browser would, and line breaks requested by block elements are deferred until
+// the next text so they never accumulate.
+type textWriter struct {
+	b            strings.Builder
+	pendingBreak int  // Newlines to write before the next text
+	pendingSpace bool // A space to write before the next text
+	listDepth    int
+}
+
+// String returns the rendered text
+func (w *textWriter) String() string {
+	lines := strings.Split(w.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// breakLine requests n newlines (1 = new line, 2 = blank line) before the next text
+func (w *textWriter) breakLine(n int) {
+	w.pendingBreak = max(w.pendingBreak, n)
+	w.pendingSpace = false
+}
+
+// write appends text, flushing pending line breaks and spaces first
+func (w *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.b.Len() > 0 {
+		if w.pendingBreak > 0 {
+			w.b.WriteString(strings.Repeat("\n", w.pendingBreak))
+		} else if w.pendingSpace {
+			w.b.WriteByte(' ')
+		}
+	}
+	w.pendingBreak = 0
+	w.pendingSpace = false
+	w.b.WriteString(s)
+}
+
+// writeText appends a text node with whitespace collapsed
+func (w *textWriter) writeText(s string) {
+	if s == "" {
+		return
+	}
+	startsSpace := isSpace(s[0])
+	endsSpace := isSpace(s[len(s)-1])
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		w.pendingSpace = w.pendingSpace || w.pendingBreak == 0
+		return
+	}
+	if startsSpace && w.pendingBreak == 0 {
+		w.pendingSpace = true
+	}
+	w.write(strings.Join(words, " "))
+	w.pendingSpace = endsSpace
+}
+
+func (w *textWriter) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.writeText(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.renderChildren(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.breakLine(2)
+		level := int(n.Data[1] - '0')
+		text := inlineText(n)
+		if text != "" {
+			w.write(strings.Repeat("#", level) + " " + text)
+		}
+		w.breakLine(2)
+
+	case atom.P:
+		w.breakLine(2)
+		w.renderChildren(n)
+		w.breakLine(2)
+
+	case atom.Br:
+		w.breakLine(1)
+
+	case atom.Hr:
+		w.breakLine(2)
+
+	case atom.Pre:
+		w.breakLine(2)
+		w.write(strings.Trim(nodeText(n), "\n"))
+		w.breakLine(2)
+
+	case atom.Ul, atom.Ol:
+		w.breakLine(1)
+		w.listDepth++
+		number := 1
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			number = start
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				w.render(c)
+				continue
+			}
+			marker := "-"
+			if n.DataAtom == atom.Ol {
+				marker = strconv.Itoa(number) + "."
+				number++
+			}
+			w.breakLine(1)
+			w.write(strings.Repeat("  ", w.listDepth-1) + marker)
+			w.pendingSpace = true
+			w.renderChildren(c)
+			w.breakLine(1)
+		}
+		w.listDepth--
+		w.breakLine(1)
+
+	case atom.Li:
+		// A list item outside a list
+		w.breakLine(1)
+		w.write("-")
+		w.pendingSpace = true
+		w.renderChildren(n)
+		w.breakLine(1)
+
+	case atom.Table:
+		w.breakLine(2)
+		w.renderTable(n)
+		w.breakLine(2)
+
+	case atom.Img:
+		// Images carry no text we can quote
+
+	default:
+		block := blockElements[n.DataAtom]
+		if block {
+			w.breakLine(1)
+		}
+		w.renderChildren(n)
+		if block {
+			w.breakLine(1)
+		}
+	}
+}
+
+func (w *textWriter) renderChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.render(c)
+	}
+}
+
+// renderTable writes a caption line and one line per row, with cells separated by " | "
+func (w *textWriter) renderTable(table *html.Node) {
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Caption:
+				if text := inlineText(c); text != "" {
+					w.breakLine(1)
+					w.write(text)
+				}
+			case atom.Tr:
+				var cells []string
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						cells = append(cells, inlineText(cell))
+					}
+				}
+				if strings.TrimSpace(strings.Join(cells, "")) != "" {
+					w.breakLine(1)
+					w.write(strings.Join(cells, " | "))
+				}
+			default:
+				visit(c)
+			}
+		}
+	}
+	visit(table)
+}
+
+// inlineText renders n and flattens the result onto a single line
+func inlineText(n *html.Node) string {
+	sub := &textWriter{}
+	sub.renderChildren(n)
+	return strings.Join(strings.Fields(sub.String()), " ")
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
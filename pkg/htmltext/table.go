@@ -0,0 +1,261 @@
+package htmltext
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxSpan caps colspan and rowspan values, which malformed pages set absurdly high
+const maxSpan = 100
+
+// timeHeader matches column headers of time dimensions, whose numeric cells
+// label their row (e.g., a "Year" column of 2019, 2020, ...)
+var timeHeader = regexp.MustCompile(`(?i)^(year|date|month|quarter|period|fiscal year|fy)\b`)
+
+// Table is an HTML table as a grid of data cells labeled by their headers
+type Table struct {
+	Index   int    // 1-based position of the table among the page's content tables
+	Caption string // Text of <caption>
+	Cells   []Cell // Data cells in row order
+}
+
+// Cell is a data cell of a table
+type Cell struct {
+	Row       int    // 0-based grid row, after expanding row spans
+	Col       int    // 0-based grid column, after expanding column spans
+	RowHeader string // Header cells at the start of the row, joined with " / "
+	ColHeader string // Header rows' cells above the column, joined with " / "
+	Value     string // Cell text
+}
+
+// Excerpt reconstructs the cell as "row header / column header: value", leaving
+// out missing headers
+func (c Cell) Excerpt() string {
+	var labels []string
+	for _, h := range []string{c.RowHeader, c.ColHeader} {
+		if h != "" {
+			labels = append(labels, h)
+		}
+	}
+	if len(labels) == 0 {
+		return c.Value
+	}
+	return strings.Join(labels, " / ") + ": " + c.Value
+}
+
+// FindExcerpt returns the cell whose reconstructed excerpt is excerpt, ignoring
+// case and whitespace differences
+func (t *Table) FindExcerpt(excerpt string) (Cell, bool) {
+	want := normalizeSpace(excerpt)
+	for _, c := range t.Cells {
+		if normalizeSpace(c.Excerpt()) == want {
+			return c, true
+		}
+	}
+	return Cell{}, false
+}
+
+// Tables parses the tables of an HTML page into cells labeled with their row and
+// column headers. Tables in boilerplate (navigation, sidebars, hidden elements)
+// are skipped, as are layout tables without data cells. Row and column spans are
+// expanded so every data cell is labeled by the headers it sits under.
+func Tables(body []byte, contentType string) ([]Table, error) {
+	root, err := html.Parse(strings.NewReader(Decode(body, contentType)))
+	if err != nil {
+		return nil, err
+	}
+	content := findFirst(root, atom.Body)
+	if content == nil {
+		content = root
+	}
+	prune(content, false)
+
+	var tables []Table
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom == atom.Table {
+				if t := parseTable(c); len(t.Cells) > 0 {
+					t.Index = len(tables) + 1
+					tables = append(tables, t)
+				}
+			}
+			visit(c)
+		}
+	}
+	visit(content)
+	return tables, nil
+}
+
+// gridCell is a slot of the expanded table grid
+type gridCell struct {
+	text   string
+	header bool // A <th>, or a cell of a header row
+	origin bool // The slot where the cell starts, rather than one it spans into
+	filled bool // Taken by a cell (slots of short rows are not)
+}
+
+// parseTable expands a table into a grid and labels its data cells
+func parseTable(table *html.Node) Table {
+	t := Table{}
+	var rows []*html.Node
+	headRows := map[*html.Node]bool{}
+	var collect func(n *html.Node, inHead bool)
+	collect = func(n *html.Node, inHead bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Caption:
+				t.Caption = inlineText(c)
+			case atom.Thead:
+				collect(c, true)
+			case atom.Tbody, atom.Tfoot:
+				collect(c, false)
+			case atom.Tr:
+				rows = append(rows, c)
+				headRows[c] = inHead
+			}
+			// Nested tables are parsed on their own
+		}
+	}
+	collect(table, false)
+
+	// Expand spans into a grid
+	var grid [][]gridCell
+	for r, tr := range rows {
+		for len(grid) <= r {
+			grid = append(grid, nil)
+		}
+		col := 0
+		for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+				continue
+			}
+			// Skip slots taken by row spans from above
+			for col < len(grid[r]) && grid[r][col].filled {
+				col++
+			}
+			colspan := spanAttr(cell, "colspan")
+			rowspan := min(spanAttr(cell, "rowspan"), len(rows)-r)
+			slot := gridCell{text: inlineText(cell), header: cell.DataAtom == atom.Th || headRows[tr], filled: true}
+			for dr := 0; dr < rowspan; dr++ {
+				for len(grid) <= r+dr {
+					grid = append(grid, nil)
+				}
+				for dc := 0; dc < colspan; dc++ {
+					for len(grid[r+dr]) <= col+dc {
+						grid[r+dr] = append(grid[r+dr], gridCell{})
+					}
+					s := slot
+					s.origin = dr == 0 && dc == 0
+					grid[r+dr][col+dc] = s
+				}
+			}
+			col += colspan
+		}
+	}
+
+	// Header rows: <thead> rows and leading rows of <th> cells. Tables marked up
+	// with <td> only get their first row as header when it holds no numbers but
+	// the rows below do.
+	headerRows := 0
+	for headerRows < len(grid) && isHeaderRow(grid[headerRows]) {
+		headerRows++
+	}
+	if headerRows == 0 && len(grid) > 1 && !hasNumbers(grid[0], 1) && hasNumbers(grid[1], 1) {
+		headerRows = 1
+	}
+
+	timeColumn := headerRows > 0 && len(grid[0]) > 0 && timeHeader.MatchString(grid[0][0].text)
+	for r := headerRows; r < len(grid); r++ {
+		row := grid[r]
+		// Row headers: leading <th> cells, else a first column of text or dates
+		headerCols := 0
+		for headerCols < len(row) && row[headerCols].header {
+			headerCols++
+		}
+		if headerCols == 0 && len(row) > 1 && (timeColumn || !hasNumbers(row[:1], 0)) {
+			headerCols = 1
+		}
+
+		rowHeader := joinDistinct(row[:headerCols])
+		for c := headerCols; c < len(row); c++ {
+			if !row[c].origin || row[c].text == "" || row[c].header {
+				continue
+			}
+			var above []gridCell
+			for h := 0; h < headerRows; h++ {
+				if c < len(grid[h]) {
+					above = append(above, grid[h][c])
+				}
+			}
+			t.Cells = append(t.Cells, Cell{
+				Row:       r,
+				Col:       c,
+				RowHeader: rowHeader,
+				ColHeader: joinDistinct(above),
+				Value:     row[c].text,
+			})
+		}
+	}
+	return t
+}
+
+// isHeaderRow reports whether every non-empty cell of a row is a header cell
+func isHeaderRow(row []gridCell) bool {
+	found := false
+	for _, c := range row {
+		if c.text == "" {
+			continue
+		}
+		if !c.header {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// hasNumbers reports whether any cell of row, from column from on, contains a digit
+func hasNumbers(row []gridCell, from int) bool {
+	for i := from; i < len(row); i++ {
+		if strings.ContainsAny(row[i].text, "0123456789") {
+			return true
+		}
+	}
+	return false
+}
+
+// joinDistinct joins the non-empty texts of cells with " / ", skipping the
+// repeats that spans produce
+func joinDistinct(cells []gridCell) string {
+	var parts []string
+	for _, c := range cells {
+		if c.text != "" && (len(parts) == 0 || parts[len(parts)-1] != c.text) {
+			parts = append(parts, c.text)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// spanAttr returns a colspan or rowspan value, 1 if missing or invalid
+func spanAttr(n *html.Node, key string) int {
+	span, err := strconv.Atoi(attr(n, key))
+	if err != nil || span < 1 {
+		return 1
+	}
+	return min(span, maxSpan)
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
@@ -0,0 +1,88 @@
+package htmltext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTables(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string // Excerpts of the first table's cells, in order
+	}{
+		{"thead and row headers", `<table><caption>EV sales</caption>
+			<thead><tr><th>Region</th><th>2022</th><th>2023</th></tr></thead>
+			<tbody><tr><th>China</th><td>5.9</td><td>8.1</td></tr>
+			<tr><th>Europe</th><td>2.7</td><td>3.2</td></tr></tbody></table>`,
+			[]string{"China / 2022: 5.9", "China / 2023: 8.1", "Europe / 2022: 2.7", "Europe / 2023: 3.2"}},
+		{"colspan header", `<table>
+			<tr><th rowspan="2">Region</th><th colspan="2">Sales (million)</th></tr>
+			<tr><th>2022</th><th>2023</th></tr>
+			<tr><th>China</th><td>5.9</td><td>8.1</td></tr></table>`,
+			[]string{"China / Sales (million) / 2022: 5.9", "China / Sales (million) / 2023: 8.1"}},
+		{"rowspan row header", `<table>
+			<tr><th>Region</th><th>Year</th><th>Sales</th></tr>
+			<tr><th rowspan="2">China</th><th>2022</th><td>5.9</td></tr>
+			<tr><th>2023</th><td>8.1</td></tr></table>`,
+			[]string{"China / 2022 / Sales: 5.9", "China / 2023 / Sales: 8.1"}},
+		{"rowspan data cell", `<table>
+			<tr><th>Model</th><th>Range</th><th>Price</th></tr>
+			<tr><td>Base</td><td rowspan="2">400 km</td><td>$40,000</td></tr>
+			<tr><td>Premium</td><td>$50,000</td></tr></table>`,
+			[]string{"Base / Range: 400 km", "Base / Price: $40,000", "Premium / Price: $50,000"}},
+		{"td-only with text header row", `<table>
+			<tr><td>Country</td><td>Share</td></tr>
+			<tr><td>Norway</td><td>82%</td></tr></table>`,
+			[]string{"Norway / Share: 82%"}},
+		{"year column labels rows", `<table>
+			<tr><th>Year</th><th>Sales</th></tr>
+			<tr><td>2022</td><td>10.2</td></tr>
+			<tr><td>2023</td><td>14</td></tr></table>`,
+			[]string{"2022 / Sales: 10.2", "2023 / Sales: 14"}},
+		{"absurd span capped", `<table>
+			<tr><th>Item</th><th colspan="100000">Value</th></tr>
+			<tr><td>A</td><td>1</td></tr></table>`,
+			[]string{"A / Value: 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := Tables([]byte("<html><body>"+tt.html+"</body></html>"), "text/html")
+			if err != nil {
+				t.Fatalf("Tables: %v", err)
+			}
+			if len(tables) != 1 {
+				t.Fatalf("got %d tables, want 1", len(tables))
+			}
+			var got []string
+			for _, c := range tables[0].Cells {
+				got = append(got, c.Excerpt())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("cells:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestTablesSkipsBoilerplateAndLayout(t *testing.T) {
+	page := `<html><body>
+		<nav><table><tr><th>Menu</th><td>1</td></tr></table></nav>
+		<table><tr><td></td></tr></table>
+		<table><caption>Sales</caption><tr><th>Year</th><th>Units</th></tr><tr><td>2023</td><td>14</td></tr></table>
+		</body></html>`
+	tables, err := Tables([]byte(page), "text/html")
+	if err != nil {
+		t.Fatalf("Tables: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Caption != "Sales" || tables[0].Index != 1 {
+		t.Fatalf("got %+v, want only the Sales table", tables)
+	}
+	cell, ok := tables[0].FindExcerpt("  2023 /  UNITS: 14 ")
+	if !ok || cell.Row != 1 || cell.Col != 1 {
+		t.Errorf("FindExcerpt = %+v, %v", cell, ok)
+	}
+	if _, ok := tables[0].FindExcerpt("2024 / Units: 14"); ok {
+		t.Error("FindExcerpt matched a missing cell")
+	}
+}
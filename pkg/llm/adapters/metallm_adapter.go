@@ -64,6 +64,15 @@ func (m *MetaLLMAdapter) Name() string {
 	return m.model
 }
 
+// SupportsResponseSchema reports false for every provider, OpenAI included:
+// MetaLLM chat requests have no response format field to carry OpenAI's
+// response_format json_schema, so the JSON schema is given in the prompt and
+// responses are validated (see llm.GenerateJSON). Only Gemini, which doesn't go
+// through MetaLLM, gets native schema-constrained output.
+func (m *MetaLLMAdapter) SupportsResponseSchema() bool {
+	return false
+}
+
 // GenerateContent implements the LLM interface
 func (m *MetaLLMAdapter) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
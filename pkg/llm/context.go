@@ -0,0 +1,74 @@
+package llm
+
+import "strings"
+
+// contextWindows are input context sizes in tokens by model name prefix; the
+// longest matching prefix wins
+var contextWindows = map[string]int{
+	"gemini-1.5-pro": 2097152,
+	"gemini-":        1048576,
+	"claude-":        200000,
+	"gpt-4o":         128000,
+	"gpt-4.1":        1047576,
+	"gpt-4-turbo":    128000,
+	"gpt-4-32k":      32768,
+	"gpt-4":          8192,
+	"gpt-5":          400000,
+	"gpt-3.5-turbo":  16385,
+	"o1":             200000,
+	"o3":             200000,
+	"o4":             200000,
+	"grok-4":         256000,
+	"grok-3":         131072,
+	"grok-2":         131072,
+	"llama3.1":       131072,
+	"llama3.2":       131072,
+	"llama3.3":       131072,
+	"llama3":         8192,
+	"mistral":        32768,
+	"mixtral":        32768,
+	"qwen2.5":        32768,
+	"qwen3":          40960,
+	"gemma2":         8192,
+	"gemma3":         131072,
+	"phi3":           4096,
+	"deepseek-r1":    131072,
+	"command-r":      131072,
+}
+
+// defaultContextWindows apply when the model is not recognized
+var defaultContextWindows = map[string]int{
+	"gemini": 1048576,
+	"claude": 200000,
+	"openai": 128000,
+	"xai":    131072,
+	"ollama": 4096, // Ollama's default num_ctx, whatever the model supports
+}
+
+// ContextWindow returns the input context size in tokens for a provider and model
+func ContextWindow(provider, modelName string) int {
+	name := strings.ToLower(modelName)
+	best, window := "", 0
+	for prefix, tokens := range contextWindows {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best, window = prefix, tokens
+		}
+	}
+	// Ollama truncates prompts to its num_ctx setting, not the model's limit
+	if window > 0 && provider != "ollama" {
+		return window
+	}
+	if tokens, ok := defaultContextWindows[provider]; ok {
+		return tokens
+	}
+	return 8192
+}
+
+// ContextWindow returns the configured model's input context size in tokens,
+// LLM_CONTEXT_TOKENS if set
+func (mf *ModelFactory) ContextWindow() int {
+	if mf.cfg.LLMContextTokens > 0 {
+		return mf.cfg.LLMContextTokens
+	}
+	return ContextWindow(mf.cfg.LLMProvider, mf.cfg.LLMModel)
+}
@@ -73,6 +73,24 @@ func (mf *ModelFactory) Close() error {
 	return nil
 }
 
+// ForModel returns a factory for another provider and model that shares this
+// factory's API keys and observability hook. An empty model name selects the
+// provider's default. LLM_API_KEY and LLM_BASE_URL apply to the configured
+// provider only, and LLM_CONTEXT_TOKENS to the configured model only.
+func (mf *ModelFactory) ForModel(provider, modelName string) *ModelFactory {
+	cfg := *mf.cfg
+	if provider != mf.cfg.LLMProvider {
+		cfg.LLMAPIKey = ""
+		cfg.LLMBaseURL = ""
+	}
+	if provider != mf.cfg.LLMProvider || modelName != mf.cfg.LLMModel {
+		cfg.LLMContextTokens = 0
+	}
+	cfg.LLMProvider = provider
+	cfg.LLMModel = modelName
+	return &ModelFactory{cfg: &cfg, obsHook: mf.obsHook}
+}
+
 // CreateModel creates an LLM model based on the configured provider
 func (mf *ModelFactory) CreateModel(ctx context.Context) (model.LLM, error) {
 	switch mf.cfg.LLMProvider {
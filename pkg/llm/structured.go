@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+// schemaSupporter is implemented by models that can report whether they accept a
+// response JSON schema. Models that don't implement it (ADK's native Gemini
+// model) are assumed to.
+type schemaSupporter interface {
+	SupportsResponseSchema() bool
+}
+
+// SupportsResponseSchema reports whether a model constrains its output to a JSON
+// schema passed in the request config
+func SupportsResponseSchema(m model.LLM) bool {
+	if s, ok := m.(schemaSupporter); ok {
+		return s.SupportsResponseSchema()
+	}
+	return true
+}
+
+// JSONOptions configure GenerateJSON
+type JSONOptions struct {
+	MaxRepairs int               // Re-prompts after an invalid response
+	Prompts    *prompts.Registry // Source of the repair prompt (prompts.JSONRepair); the embedded prompts if nil
+}
+
+// embeddedPrompts is the registry used when JSONOptions names none
+var embeddedPrompts = sync.OnceValue(prompts.Default)
+
+// GenerateJSON asks a model for JSON matching the schema of T and decodes it.
+// The schema is inferred from T (fields without omitempty are required; see
+// jsonschema.For) and passed to the provider as a response schema when it
+// supports one; otherwise it is appended to the prompt. A response that is not
+// valid JSON or does not match the schema is sent back to the model with the
+// validation error in the repair prompt, up to opts.MaxRepairs times, before
+// GenerateJSON gives up.
+func GenerateJSON[T any](ctx context.Context, m model.LLM, prompt string, opts JSONOptions) (T, error) {
+	var zero T
+	registry := opts.Prompts
+	if registry == nil {
+		registry = embeddedPrompts()
+	}
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return zero, fmt.Errorf("failed to infer response schema: %w", err)
+	}
+	allowAdditionalProperties(schema)
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return zero, fmt.Errorf("failed to resolve response schema: %w", err)
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return zero, fmt.Errorf("failed to encode response schema: %w", err)
+	}
+
+	req := &model.LLMRequest{}
+	if SupportsResponseSchema(m) {
+		req.Config = &genai.GenerateContentConfig{
+			ResponseMIMEType:   "application/json",
+			ResponseJsonSchema: schema,
+		}
+	} else {
+		prompt += "\n\nRespond with JSON only, matching this JSON Schema:\n" + string(schemaJSON)
+	}
+	req.Contents = genai.Text(prompt)
+
+	for attempt := 0; ; attempt++ {
+		response, err := GenerateText(ctx, m, req)
+		if err != nil {
+			return zero, err
+		}
+
+		value, err := decodeJSON[T](response, resolved)
+		if err == nil {
+			return value, nil
+		}
+		if attempt >= opts.MaxRepairs {
+			return zero, fmt.Errorf("invalid LLM response after %d attempts: %w (response: %s)", attempt+1, err, response)
+		}
+
+		log.Printf("LLM: Invalid structured response (%v), asking for a repair (%d/%d)", err, attempt+1, opts.MaxRepairs)
+		repair, _, renderErr := registry.Render(prompts.JSONRepair, "", struct{ Error, Schema string }{err.Error(), string(schemaJSON)})
+		if renderErr != nil {
+			return zero, renderErr
+		}
+		req.Contents = append(req.Contents,
+			genai.NewContentFromText(response, genai.RoleModel),
+			genai.NewContentFromText(repair, genai.RoleUser))
+	}
+}
+
+// GenerateText sends a request to a model and returns the text of its response
+func GenerateText(ctx context.Context, m model.LLM, req *model.LLMRequest) (string, error) {
+	var response string
+	for llmResp, err := range m.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", fmt.Errorf("LLM generation failed: %w", err)
+		}
+		if llmResp.Content != nil && llmResp.Content.Parts != nil {
+			for _, part := range llmResp.Content.Parts {
+				if part.Text != "" {
+					response += part.Text
+				}
+			}
+		}
+	}
+	return response, nil
+}
+
+// decodeJSON parses a response as JSON, validates it against the schema and
+// decodes it into T. Markdown code fences and text around the JSON are ignored.
+func decodeJSON[T any](response string, schema *jsonschema.Resolved) (T, error) {
+	var value T
+	data := []byte(trimToJSON(response))
+
+	var instance any
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return value, fmt.Errorf("response is not JSON: %w", err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// trimToJSON strips markdown code fences and any text before the first or after
+// the last bracket of the JSON value
+func trimToJSON(response string) string {
+	response = strings.TrimSpace(response)
+	if json.Valid([]byte(response)) {
+		return response
+	}
+	start := strings.IndexAny(response, "[{")
+	if start == -1 {
+		return response
+	}
+	closing := "]"
+	if response[start] == '{' {
+		closing = "}"
+	}
+	end := strings.LastIndex(response, closing)
+	if end < start {
+		return response
+	}
+	return response[start : end+1]
+}
+
+// allowAdditionalProperties lets objects carry fields the schema doesn't name.
+// Models often add fields of their own; they are ignored when decoding, so they
+// should not fail validation.
+func allowAdditionalProperties(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	s.AdditionalProperties = nil
+	for _, p := range s.Properties {
+		allowAdditionalProperties(p)
+	}
+	allowAdditionalProperties(s.Items)
+}
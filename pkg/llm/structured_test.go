@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// scriptedModel answers successive requests with successive responses and
+// records the last user message of each request
+type scriptedModel struct {
+	responses []string
+	prompts   []string
+	schema    bool
+}
+
+func (m *scriptedModel) Name() string { return "scripted" }
+
+func (m *scriptedModel) SupportsResponseSchema() bool { return m.schema }
+
+func (m *scriptedModel) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.prompts = append(m.prompts, req.Contents[len(req.Contents)-1].Parts[0].Text)
+		response := m.responses[0]
+		if len(m.responses) > 1 {
+			m.responses = m.responses[1:]
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(response, genai.RoleModel)}, nil)
+	}
+}
+
+type rating struct {
+	ID    int     `json:"id"`
+	Score float64 `json:"score"`
+}
+
+func TestGenerateJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		responses   []string
+		maxRepairs  int
+		want        []rating
+		wantErr     string
+		wantPrompts int
+	}{
+		{"valid", []string{`[{"id": 1, "score": 0.5}]`}, 0, []rating{{1, 0.5}}, "", 1},
+		{"fenced with extra fields", []string{"```json\n[{\"id\": 2, \"score\": 1, \"reason\": \"on topic\"}]\n```"}, 0, []rating{{2, 1}}, "", 1},
+		{"repaired", []string{`[{"id": 1}]`, `[{"id": 1, "score": 0.2}]`}, 1, []rating{{1, 0.2}}, "", 2},
+		{"not json without repairs", []string{"I cannot rate these."}, 0, nil, "after 1 attempts", 1},
+		{"repairs exhausted", []string{`[{"score": 0.2}]`}, 2, nil, "after 3 attempts", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &scriptedModel{responses: tt.responses}
+			got, err := GenerateJSON[[]rating](context.Background(), m, "Rate the statistics.", JSONOptions{MaxRepairs: tt.maxRepairs})
+			if len(m.prompts) != tt.wantPrompts {
+				t.Errorf("sent %d requests, want %d", len(m.prompts), tt.wantPrompts)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateJSON: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("rating %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateJSONPrompts(t *testing.T) {
+	m := &scriptedModel{responses: []string{`{}`, `[]`}}
+	if _, err := GenerateJSON[[]rating](context.Background(), m, "Rate the statistics.", JSONOptions{MaxRepairs: 1}); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	// Without a response schema the schema goes in the prompt
+	if !strings.Contains(m.prompts[0], "matching this JSON Schema") || !strings.Contains(m.prompts[0], `"score"`) {
+		t.Errorf("first prompt lacks the schema:\n%s", m.prompts[0])
+	}
+	// The repair prompt comes from the llm.repair template
+	if !strings.Contains(m.prompts[1], "not valid: ") || !strings.Contains(m.prompts[1], `"score"`) {
+		t.Errorf("repair prompt lacks the error or schema:\n%s", m.prompts[1])
+	}
+
+	m = &scriptedModel{responses: []string{`[]`}, schema: true}
+	if _, err := GenerateJSON[[]rating](context.Background(), m, "Rate the statistics.", JSONOptions{}); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if m.prompts[0] != "Rate the statistics." {
+		t.Errorf("prompt with a response schema = %q", m.prompts[0])
+	}
+}
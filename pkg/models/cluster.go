@@ -0,0 +1,189 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Corroboration is another source stating the same fact as a statistic
+type Corroboration struct {
+	Source     string `json:"source"`
+	SourceURL  string `json:"source_url"`
+	SourceTier string `json:"source_tier,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	Excerpt    string `json:"excerpt"`
+}
+
+// Similarity thresholds for SameFact, as the share of the shorter text's words
+// found in the other
+const (
+	nameOverlap    = 0.6
+	excerptOverlap = 0.5
+)
+
+// ClusterStatistics merges statistics that state the same fact (see SameFact),
+// so one figure quoted by several sites is listed once. The first statistic of
+// each cluster is kept as its representative, with the others attached as
+// Corroborating sources; rank the statistics first (reputation.RankStatistics)
+// so it is the best-sourced one. Corroborations of merged statistics carry over,
+// so clustering an already clustered list is safe.
+func ClusterStatistics(stats []Statistic) []Statistic {
+	var clusters []Statistic
+	var members [][]*Statistic // Each cluster's statistics, compared against new ones
+	for i := range stats {
+		s := &stats[i]
+		joined := false
+		for c := range clusters {
+			for _, m := range members[c] {
+				if SameFact(s, m) {
+					clusters[c].Corroborating = append(clusters[c].Corroborating, s.corroboration())
+					clusters[c].Corroborating = append(clusters[c].Corroborating, s.Corroborating...)
+					members[c] = append(members[c], s)
+					joined = true
+					break
+				}
+			}
+			if joined {
+				break
+			}
+		}
+		if !joined {
+			rep := *s
+			rep.Corroborating = append([]Corroboration(nil), s.Corroborating...)
+			clusters = append(clusters, rep)
+			members = append(members, []*Statistic{s})
+		}
+	}
+	for c := range clusters {
+		clusters[c].Corroborating = dropSameSource(clusters[c].SourceURL, clusters[c].Corroborating)
+	}
+	return clusters
+}
+
+// SameFact reports whether two statistics state the same fact: equal values and
+// units, no conflicting period (years, and quarters or months where both give
+// them) or geography, and similar names or excerpts
+func SameFact(a, b *Statistic) bool {
+	if !ValuesEqual(a.Value, b.Value) || (a.ValueKind == ValueKindRange) != (b.ValueKind == ValueKindRange) {
+		return false
+	}
+	if a.ValueKind == ValueKindRange && !(pointersEqual(a.ValueMin, b.ValueMin) && pointersEqual(a.ValueMax, b.ValueMax)) {
+		return false
+	}
+	if normalizeUnit(a.Unit) != normalizeUnit(b.Unit) {
+		return false
+	}
+
+	if aFrom, aTo, ok := a.Period.YearRange(); ok {
+		if bFrom, bTo, ok := b.Period.YearRange(); ok && (aFrom != bFrom || aTo != bTo) {
+			return false
+		}
+	}
+	// Quarters and months of one year are different facts (2023 Q1 vs. Q2)
+	if aFrom, aTo, ok := a.Period.MonthRange(); ok {
+		if bFrom, bTo, ok := b.Period.MonthRange(); ok && (aFrom != bFrom || aTo != bTo) {
+			return false
+		}
+	}
+	if ac, bc := a.Geography.country(), b.Geography.country(); ac != "" && bc != "" && !strings.EqualFold(ac, bc) {
+		return false
+	}
+	if a.Geography != nil && b.Geography != nil && a.Geography.RegionCode != "" && b.Geography.RegionCode != "" &&
+		!strings.EqualFold(a.Geography.RegionCode, b.Geography.RegionCode) {
+		return false
+	}
+
+	return overlap(a.Name, b.Name) >= nameOverlap || overlap(a.Excerpt, b.Excerpt) >= excerptOverlap
+}
+
+func (s *Statistic) corroboration() Corroboration {
+	return Corroboration{
+		Source:     s.Source,
+		SourceURL:  s.SourceURL,
+		SourceTier: s.SourceTier,
+		Page:       s.Page,
+		Excerpt:    s.Excerpt,
+	}
+}
+
+// dropSameSource removes corroborations from the representative's own URL and
+// repeats of one URL, keeping the first
+func dropSameSource(sourceURL string, corroborations []Corroboration) []Corroboration {
+	seen := map[string]bool{sourceURL: true}
+	kept := corroborations[:0]
+	for _, c := range corroborations {
+		if seen[c.SourceURL] {
+			continue
+		}
+		seen[c.SourceURL] = true
+		kept = append(kept, c)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+func pointersEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return ValuesEqual(*a, *b)
+}
+
+// unitSynonyms maps spellings of a unit to one name
+var unitSynonyms = map[string]string{
+	"%": "percent", "pct": "percent", "per cent": "percent", "percentage": "percent",
+	"usd": "$", "dollars": "$", "us dollars": "$",
+	"persons": "people", "individuals": "people",
+}
+
+// normalizeUnit lowercases a unit and maps synonyms to one spelling
+func normalizeUnit(unit string) string {
+	u := strings.Join(strings.Fields(strings.ToLower(unit)), " ")
+	if s, ok := unitSynonyms[u]; ok {
+		return s
+	}
+	return u
+}
+
+// stopWords are left out of text similarity
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "by": true, "for": true, "from": true,
+	"in": true, "is": true, "of": true, "on": true, "or": true, "the": true, "to": true, "was": true,
+	"were": true, "with": true,
+}
+
+// overlap returns the share of the shorter text's distinct words that appear in
+// the other, 0 if either has none
+func overlap(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa))
+}
+
+// words returns the distinct lowercase words of text, without stop words and
+// numbers (values are compared separately, and shared numbers would make any two
+// excerpts of the value look alike)
+func words(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if !stopWords[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
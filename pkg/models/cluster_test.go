@@ -0,0 +1,145 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSameFact(t *testing.T) {
+	base := Statistic{Name: "Global electric car sales", Value: 14e6, Unit: "cars", Excerpt: "Electric car sales neared 14 million in 2023",
+		Period: &Period{Year: 2023}, Geography: &Geography{RegionCode: "WORLD"}}
+	lo, hi, otherHi := 79.0, 96.0, 90.0
+	tests := []struct {
+		name   string
+		modify func(s *Statistic)
+		want   bool
+	}{
+		{"identical", func(s *Statistic) {}, true},
+		{"similar name", func(s *Statistic) { s.Name = "Electric car sales, global"; s.Excerpt = "" }, true},
+		{"similar excerpt", func(s *Statistic) { s.Name = "EV market"; s.Excerpt = "sales of electric cars neared 14 million" }, true},
+		{"different value", func(s *Statistic) { s.Value = 10.2e6 }, false},
+		{"unit spelling", func(s *Statistic) { s.Unit = " Cars" }, true},
+		{"different unit", func(s *Statistic) { s.Unit = "$" }, false},
+		{"different year", func(s *Statistic) { s.Period = &Period{Year: 2022} }, false},
+		{"same year as a date range", func(s *Statistic) { s.Period = &Period{StartDate: "2023-01", EndDate: "2023-12"} }, true},
+		{"no period", func(s *Statistic) { s.Period = nil }, true},
+		{"year against quarter", func(s *Statistic) { s.Period = &Period{Year: 2023, Quarter: 2} }, true},
+		{"quarter as a date range", func(s *Statistic) { s.Period = &Period{StartDate: "2023-04", EndDate: "2023-06"} }, true},
+		{"different country", func(s *Statistic) { s.Geography = &Geography{CountryCode: "US"} }, false},
+		{"unrelated text", func(s *Statistic) { s.Name = "Population"; s.Excerpt = "The city grew to 14 million residents" }, false},
+		{"range against point", func(s *Statistic) { s.ValueKind = ValueKindRange; s.ValueMin, s.ValueMax = &lo, &hi }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.modify(&other)
+			if got := SameFact(&base, &other); got != tt.want {
+				t.Errorf("SameFact = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	periods := []struct {
+		a, b Period
+		want bool
+	}{
+		{Period{Year: 2023, Quarter: 1}, Period{Year: 2023, Quarter: 2}, false},
+		{Period{Year: 2023, Quarter: 1}, Period{StartDate: "2023-01-01", EndDate: "2023-03-31"}, true},
+		{Period{Year: 2023, Quarter: 1}, Period{StartDate: "2023-01", EndDate: "2023-12"}, false},
+		{Period{StartDate: "2023-01"}, Period{StartDate: "2023-02"}, false},
+		{Period{StartDate: "2023-01"}, Period{StartDate: "2023"}, true},
+	}
+	for _, tt := range periods {
+		a, b := base, base
+		a.Period, b.Period = &tt.a, &tt.b
+		if got := SameFact(&a, &b); got != tt.want {
+			t.Errorf("SameFact(%s, %s) = %v, want %v", a.Period, b.Period, got, tt.want)
+		}
+	}
+
+	pct := Statistic{Name: "Firms using AI", Value: 79, ValueKind: ValueKindRange, ValueMin: &lo, ValueMax: &hi, Unit: "%"}
+	same := pct
+	same.Unit = "percent"
+	other := pct
+	other.ValueMax = &otherHi
+	if !SameFact(&pct, &same) {
+		t.Error("ranges with synonymous units differ")
+	}
+	if SameFact(&pct, &other) {
+		t.Error("ranges with different ends are the same fact")
+	}
+}
+
+func TestClusterStatistics(t *testing.T) {
+	sales := func(url, name string) Statistic {
+		return Statistic{Name: name, Value: 14e6, Source: url, SourceURL: "https://" + url, Excerpt: name + " neared 14 million"}
+	}
+	tests := []struct {
+		name  string
+		stats []Statistic
+		want  []string // Each cluster as "representative URL <- corroborating URLs"
+	}{
+		{"empty", nil, nil},
+		{"distinct facts", []Statistic{
+			sales("iea.org", "Electric car sales"),
+			{Name: "EV share", Value: 18, Unit: "%", SourceURL: "https://iea.org", Excerpt: "18% of cars sold"},
+		}, []string{"https://iea.org <- ", "https://iea.org <- "}},
+		{"corroborated", []Statistic{
+			sales("iea.org", "Electric car sales"),
+			sales("reuters.com", "Electric car sales worldwide"),
+			sales("bbc.co.uk", "Global electric car sales"),
+		}, []string{"https://iea.org <- https://reuters.com,https://bbc.co.uk"}},
+		{"same source and repeats dropped", []Statistic{
+			sales("iea.org", "Electric car sales"),
+			sales("iea.org", "Electric car sales"),
+			sales("reuters.com", "Electric car sales"),
+			sales("reuters.com", "Electric car sales"),
+		}, []string{"https://iea.org <- https://reuters.com"}},
+		{"joins through a member", []Statistic{
+			{Name: "Electric car sales", Value: 14e6, SourceURL: "https://a.org", Excerpt: "electric car sales"},
+			{Name: "Electric car sales in 2023 worldwide", Value: 14e6, SourceURL: "https://b.org", Excerpt: "worldwide sales of battery cars"},
+			{Name: "Worldwide battery car sales", Value: 14e6, SourceURL: "https://c.org", Excerpt: "worldwide sales of battery cars"},
+		}, []string{"https://a.org <- https://b.org,https://c.org"}},
+		{"different quarters", []Statistic{
+			{Name: "Electric car sales", Value: 3e6, SourceURL: "https://a.org", Period: &Period{Year: 2023, Quarter: 1}},
+			{Name: "Electric car sales", Value: 3e6, SourceURL: "https://b.org", Period: &Period{Year: 2023, Quarter: 2}},
+			{Name: "Electric car sales", Value: 3e6, SourceURL: "https://c.org", Period: &Period{Year: 2023, Quarter: 1}},
+		}, []string{"https://a.org <- https://c.org", "https://b.org <- "}},
+		{"carries corroborations over", []Statistic{
+			{Name: "Electric car sales", Value: 14e6, SourceURL: "https://a.org", Corroborating: []Corroboration{{SourceURL: "https://b.org"}}},
+			{Name: "Electric car sales", Value: 14e6, SourceURL: "https://c.org", Corroborating: []Corroboration{{SourceURL: "https://d.org"}, {SourceURL: "https://a.org"}}},
+		}, []string{"https://a.org <- https://b.org,https://c.org,https://d.org"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := ClusterStatistics(tt.stats)
+			var got []string
+			for _, c := range clusters {
+				var urls []string
+				for _, co := range c.Corroborating {
+					urls = append(urls, co.SourceURL)
+				}
+				got = append(got, c.SourceURL+" <- "+strings.Join(urls, ","))
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("clusters = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterStatisticsIdempotent(t *testing.T) {
+	stats := []Statistic{
+		{Name: "Electric car sales", Value: 14e6, SourceURL: "https://a.org"},
+		{Name: "Electric car sales", Value: 14e6, SourceURL: "https://b.org"},
+		{Name: "EV share", Value: 18, SourceURL: "https://a.org"},
+	}
+	once := ClusterStatistics(stats)
+	twice := ClusterStatistics(once)
+	if len(once) != 2 || len(twice) != 2 || len(twice[0].Corroborating) != 1 {
+		t.Errorf("clustering twice changed the result: %+v then %+v", once, twice)
+	}
+	if stats[0].Corroborating != nil {
+		t.Error("ClusterStatistics modified its input")
+	}
+}
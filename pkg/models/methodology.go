@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Methodology describes how the survey or poll behind a statistic was conducted
+type Methodology struct {
+	SampleSize      int      `json:"sample_size,omitempty"`      // Number of respondents (e.g., 10221)
+	MarginOfError   *float64 `json:"margin_of_error,omitempty"`  // Margin of error in percentage points (e.g., 1.5 for ±1.5)
+	ConfidenceLevel *float64 `json:"confidence_level,omitempty"` // Confidence level in percent (e.g., 95)
+	CILower         *float64 `json:"ci_lower,omitempty"`         // Lower end of the reported confidence interval
+	CIUpper         *float64 `json:"ci_upper,omitempty"`         // Upper end of the reported confidence interval
+	Method          string   `json:"method,omitempty"`           // Collection method (e.g., "online panel survey", "telephone poll")
+	FieldDates      string   `json:"field_dates,omitempty"`      // When responses were collected (e.g., "March 7-13, 2024")
+}
+
+// IsEmpty reports whether no methodology details were captured
+func (m *Methodology) IsEmpty() bool {
+	return m == nil || (m.SampleSize == 0 && m.MarginOfError == nil && m.ConfidenceLevel == nil &&
+		m.CILower == nil && m.CIUpper == nil && m.Method == "" && m.FieldDates == "")
+}
+
+// String renders the methodology for display
+// (e.g., "n=10,221; ±1.5 pts at 95% confidence; online panel survey; fielded March 7-13, 2024")
+func (m *Methodology) String() string {
+	if m.IsEmpty() {
+		return ""
+	}
+
+	var parts []string
+	if m.SampleSize > 0 {
+		parts = append(parts, "n="+groupThousands(m.SampleSize))
+	}
+	if m.MarginOfError != nil {
+		moe := "±" + formatNumber(*m.MarginOfError) + " pts"
+		if m.ConfidenceLevel != nil {
+			moe += " at " + formatNumber(*m.ConfidenceLevel) + "% confidence"
+		}
+		parts = append(parts, moe)
+	}
+	if m.CILower != nil && m.CIUpper != nil {
+		ci := fmt.Sprintf("CI %s-%s", formatNumber(*m.CILower), formatNumber(*m.CIUpper))
+		if m.MarginOfError == nil && m.ConfidenceLevel != nil {
+			ci = formatNumber(*m.ConfidenceLevel) + "% " + ci
+		}
+		parts = append(parts, ci)
+	}
+	if m.Method != "" {
+		parts = append(parts, m.Method)
+	}
+	if m.FieldDates != "" {
+		parts = append(parts, "fielded "+m.FieldDates)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// groupThousands formats an integer with comma thousands separators
+func groupThousands(n int) string {
+	if n < 0 {
+		return "-" + groupThousands(-n)
+	}
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Period is the reference period a statistic describes ("as of when")
+type Period struct {
+	Year      int    `json:"year,omitempty"`       // Reference year (e.g., 2023)
+	Quarter   int    `json:"quarter,omitempty"`    // Quarter within Year (1-4)
+	StartDate string `json:"start_date,omitempty"` // Start of a date range (YYYY, YYYY-MM or YYYY-MM-DD)
+	EndDate   string `json:"end_date,omitempty"`   // End of a date range (YYYY, YYYY-MM or YYYY-MM-DD)
+}
+
+// Geography is where a statistic was measured
+type Geography struct {
+	CountryCode string `json:"country_code,omitempty"` // ISO 3166-1 alpha-2 code (e.g., "US")
+	RegionCode  string `json:"region_code,omitempty"`  // ISO 3166-2 subdivision or region code (e.g., "US-CA", "EU")
+	Name        string `json:"name,omitempty"`         // Place name as described by the source (e.g., "California")
+}
+
+// YearRange returns the first and last calendar years covered by the period
+func (p *Period) YearRange() (from, to int, ok bool) {
+	if p == nil {
+		return 0, 0, false
+	}
+	if p.Year > 0 {
+		return p.Year, p.Year, true
+	}
+	from, fromOK := leadingYear(p.StartDate)
+	to, toOK := leadingYear(p.EndDate)
+	switch {
+	case fromOK && toOK:
+		return from, to, true
+	case fromOK:
+		return from, from, true
+	case toOK:
+		return to, to, true
+	}
+	return 0, 0, false
+}
+
+// MonthRange returns the first and last months covered by the period, counted as
+// year*12 + month-1, when it is given to the month: a quarter, or dates with months.
+// Year-only periods are not, so they are left to YearRange.
+func (p *Period) MonthRange() (from, to int, ok bool) {
+	if p == nil {
+		return 0, 0, false
+	}
+	if p.Year > 0 {
+		if p.Quarter < 1 || p.Quarter > 4 {
+			return 0, 0, false
+		}
+		from = p.Year*12 + (p.Quarter-1)*3
+		return from, from + 2, true
+	}
+	if p.StartDate == "" && p.EndDate == "" {
+		return 0, 0, false
+	}
+	from, fromOK := leadingMonth(p.StartDate)
+	to, toOK := leadingMonth(p.EndDate)
+	switch {
+	case p.StartDate != "" && p.EndDate != "":
+		return from, to, fromOK && toOK
+	case p.StartDate != "":
+		return from, from, fromOK
+	}
+	return to, to, toOK
+}
+
+// String renders the period for display (e.g., "2023 Q2", "2019-01 to 2021-12")
+func (p *Period) String() string {
+	if p == nil {
+		return ""
+	}
+	if p.Year > 0 {
+		if p.Quarter > 0 {
+			return fmt.Sprintf("%d Q%d", p.Year, p.Quarter)
+		}
+		return strconv.Itoa(p.Year)
+	}
+	switch {
+	case p.StartDate != "" && p.EndDate != "":
+		return p.StartDate + " to " + p.EndDate
+	case p.StartDate != "":
+		return "from " + p.StartDate
+	case p.EndDate != "":
+		return "through " + p.EndDate
+	}
+	return ""
+}
+
+// String renders the geography for display (e.g., "California (US-CA)")
+func (g *Geography) String() string {
+	if g == nil {
+		return ""
+	}
+	code := g.RegionCode
+	if code == "" {
+		code = g.CountryCode
+	}
+	switch {
+	case g.Name != "" && code != "":
+		return fmt.Sprintf("%s (%s)", g.Name, code)
+	case g.Name != "":
+		return g.Name
+	}
+	return code
+}
+
+// country returns the ISO country code, falling back to the prefix of the region code
+func (g *Geography) country() string {
+	if g == nil {
+		return ""
+	}
+	if g.CountryCode != "" {
+		return g.CountryCode
+	}
+	if i := strings.Index(g.RegionCode, "-"); i > 0 {
+		return g.RegionCode[:i]
+	}
+	return g.RegionCode
+}
+
+// AcceptsScope reports whether a statistic with the given period and geography passes
+// the request's period and country filters. Statistics without the scope a filter
+// needs are rejected, since they cannot be shown to match.
+func (r *OrchestrationRequest) AcceptsScope(period *Period, geography *Geography) bool {
+	if len(r.Countries) > 0 {
+		country := geography.country()
+		matched := false
+		for _, c := range r.Countries {
+			if strings.EqualFold(c, country) || (geography != nil && strings.EqualFold(c, geography.RegionCode)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if r.PeriodFrom > 0 || r.PeriodTo > 0 {
+		from, to, ok := period.YearRange()
+		if !ok {
+			return false
+		}
+		if r.PeriodFrom > 0 && to < r.PeriodFrom {
+			return false
+		}
+		if r.PeriodTo > 0 && from > r.PeriodTo {
+			return false
+		}
+	}
+
+	return true
+}
+
+// leadingYear parses the year prefix of a YYYY, YYYY-MM or YYYY-MM-DD date
+func leadingYear(date string) (int, bool) {
+	if len(date) < 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil || year <= 0 {
+		return 0, false
+	}
+	return year, true
+}
+
+// leadingMonth returns the month of a YYYY-MM or YYYY-MM-DD date as year*12 + month-1
+func leadingMonth(date string) (int, bool) {
+	year, ok := leadingYear(date)
+	if !ok || len(date) < 7 || date[4] != '-' {
+		return 0, false
+	}
+	month, err := strconv.Atoi(date[5:7])
+	if err != nil || month < 1 || month > 12 {
+		return 0, false
+	}
+	return year*12 + month - 1, true
+}
+
+// FilterCandidates returns the candidates that pass the request's scope filters
+func (r *OrchestrationRequest) FilterCandidates(candidates []CandidateStatistic) []CandidateStatistic {
+	if len(r.Countries) == 0 && r.PeriodFrom == 0 && r.PeriodTo == 0 {
+		return candidates
+	}
+	kept := make([]CandidateStatistic, 0, len(candidates))
+	for _, c := range candidates {
+		if r.AcceptsScope(c.Period, c.Geography) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format of search date bounds (YYYY-MM-DD)
+const DateLayout = "2006-01-02"
+
+// SearchOptions narrows web search by locale, publication date and site. Providers
+// that cannot apply a filter natively have their results filtered afterwards.
+type SearchOptions struct {
+	Language        string   `json:"language,omitempty"`         // Search language (ISO 639-1, e.g., "de"); defaults to "en"
+	Country         string   `json:"country,omitempty"`          // Search market (ISO 3166-1 alpha-2, e.g., "DE"); defaults to "US"
+	PublishedAfter  string   `json:"published_after,omitempty"`  // Only pages published on or after this date (YYYY-MM-DD)
+	PublishedBefore string   `json:"published_before,omitempty"` // Only pages published before this date (YYYY-MM-DD)
+	SiteInclude     []string `json:"site_include,omitempty"`     // Only search these domains (e.g., ["cdc.gov", "gov"])
+	SiteExclude     []string `json:"site_exclude,omitempty"`     // Never search these domains
+}
+
+// DateRange parses the publication date bounds. Unset bounds are returned as zero times.
+func (o *SearchOptions) DateRange() (after, before time.Time, err error) {
+	if o.PublishedAfter != "" {
+		if after, err = time.Parse(DateLayout, o.PublishedAfter); err != nil {
+			return after, before, fmt.Errorf("invalid published_after %q (use YYYY-MM-DD)", o.PublishedAfter)
+		}
+	}
+	if o.PublishedBefore != "" {
+		if before, err = time.Parse(DateLayout, o.PublishedBefore); err != nil {
+			return after, before, fmt.Errorf("invalid published_before %q (use YYYY-MM-DD)", o.PublishedBefore)
+		}
+	}
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return after, before, fmt.Errorf("published_after %s must be before published_before %s", o.PublishedAfter, o.PublishedBefore)
+	}
+	return after, before, nil
+}
@@ -1,27 +1,122 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Statistic represents a verified statistic with its source
 type Statistic struct {
-	Name      string    `json:"name"`       // Name/description of the statistic
-	Value     float32   `json:"value"`      // Numerical value
-	Unit      string    `json:"unit"`       // Unit of measurement (e.g., "°C", "%", "million")
-	Source    string    `json:"source"`     // Name of the source (e.g., "Pew Research Center")
-	SourceURL string    `json:"source_url"` // URL to the source
-	Excerpt   string    `json:"excerpt"`    // Verbatim quote containing the statistic
-	Verified  bool      `json:"verified"`   // Whether this has been verified by verification agent
-	DateFound time.Time `json:"date_found"` // When this statistic was found
+	Name          string          `json:"name"`                     // Name/description of the statistic
+	Value         float64         `json:"value"`                    // Normalized numerical value in full ("1.2 million" is 1200000; lower end for ranges), used for sorting and comparison
+	ValueText     string          `json:"value_text,omitempty"`     // Literal value as written in the excerpt (e.g., "1.2 million", "45%")
+	ValueKind     ValueKind       `json:"value_kind,omitempty"`     // How to read the value (point, range, bound, approximate)
+	ValueMin      *float64        `json:"value_min,omitempty"`      // Lower end of a range
+	ValueMax      *float64        `json:"value_max,omitempty"`      // Upper end of a range
+	Unit          string          `json:"unit"`                     // Unit of measurement without magnitude words, which Value includes (e.g., "°C", "%", "USD")
+	Period        *Period         `json:"period,omitempty"`         // Reference period the statistic describes
+	Geography     *Geography      `json:"geography,omitempty"`      // Where the statistic was measured
+	Population    string          `json:"population,omitempty"`     // Who was measured (e.g., "U.S. adults 18+")
+	Methodology   *Methodology    `json:"methodology,omitempty"`    // Survey sample size, margin of error and method, when reported
+	Source        string          `json:"source"`                   // Name of the source (e.g., "Pew Research Center")
+	SourceURL     string          `json:"source_url"`               // URL to the source
+	Publisher     string          `json:"publisher,omitempty"`      // Publisher the page declares (meta tags, JSON-LD)
+	Author        string          `json:"author,omitempty"`         // Authors of the page, joined with "; "
+	PublishedDate string          `json:"published_date,omitempty"` // When the page was published (YYYY-MM-DD)
+	ModifiedDate  string          `json:"modified_date,omitempty"`  // When the page was last modified (YYYY-MM-DD)
+	Page          int             `json:"page,omitempty"`           // PDF page the excerpt is on (1-based)
+	Table         int             `json:"table,omitempty"`          // HTML table the excerpt was reconstructed from (1-based)
+	SourceTier    string          `json:"source_tier,omitempty"`    // Reputation tier of the source domain (e.g., "primary_government")
+	Relevance     *float64        `json:"relevance,omitempty"`      // Relevance to the requested topic (0-1), if scored
+	PromptID      string          `json:"prompt_id,omitempty"`      // Prompt that extracted the statistic (see prompts.Registry)
+	PromptVariant string          `json:"prompt_variant,omitempty"` // Variant of that prompt
+	PromptVersion string          `json:"prompt_version,omitempty"` // Version of that prompt variant
+	AgreedModels  []string        `json:"agreed_models,omitempty"`  // Ensemble models that extracted the statistic (SYNTHESIS_ENSEMBLE)
+	Excerpt       string          `json:"excerpt"`                  // Verbatim quote containing the statistic
+	Verified      bool            `json:"verified"`                 // Whether this has been verified by verification agent
+	Corroborating []Corroboration `json:"corroborating,omitempty"`  // Other sources stating the same fact (see ClusterStatistics)
+	DateFound     time.Time       `json:"date_found"`               // When this statistic was found
 }
 
 // CandidateStatistic represents an unverified statistic from research
 type CandidateStatistic struct {
-	Name      string  `json:"name"`
-	Value     float32 `json:"value"`
-	Unit      string  `json:"unit"`
-	Source    string  `json:"source"`
-	SourceURL string  `json:"source_url"`
-	Excerpt   string  `json:"excerpt"`
+	Name          string       `json:"name"`
+	Value         float64      `json:"value"`
+	ValueText     string       `json:"value_text,omitempty"`
+	ValueKind     ValueKind    `json:"value_kind,omitempty"`
+	ValueMin      *float64     `json:"value_min,omitempty"`
+	ValueMax      *float64     `json:"value_max,omitempty"`
+	Unit          string       `json:"unit"`
+	Period        *Period      `json:"period,omitempty"`
+	Geography     *Geography   `json:"geography,omitempty"`
+	Population    string       `json:"population,omitempty"`
+	Methodology   *Methodology `json:"methodology,omitempty"`
+	Source        string       `json:"source"` // Publisher name when the page declares one, else the domain
+	SourceURL     string       `json:"source_url"`
+	Publisher     string       `json:"publisher,omitempty"`
+	Author        string       `json:"author,omitempty"`
+	PublishedDate string       `json:"published_date,omitempty"` // From page metadata, else the search provider (YYYY-MM-DD)
+	ModifiedDate  string       `json:"modified_date,omitempty"`
+	Page          int          `json:"page,omitempty"`  // PDF page the excerpt is on (1-based)
+	Table         int          `json:"table,omitempty"` // HTML table (1-based, see htmltext.Tables) whose cell the excerpt reconstructs as "row header / column header: value"
+	Excerpt       string       `json:"excerpt"`
+	ChunkOffset   int          `json:"chunk_offset,omitempty"`  // Byte offset in the source text of the chunk the statistic was extracted from
+	ScanMismatch  string       `json:"scan_mismatch,omitempty"` // Why the number scanner couldn't confirm the value and excerpt (flag mode)
+	Relevance     *float64     `json:"relevance,omitempty"`     // Relevance to the synthesis topic, from 0 (unrelated) to 1; nil if not scored
+	PromptID      string       `json:"prompt_id,omitempty"`     // Prompt that extracted the statistic, with its variant and version
+	PromptVariant string       `json:"prompt_variant,omitempty"`
+	PromptVersion string       `json:"prompt_version,omitempty"`
+	AgreedModels  []string     `json:"agreed_models,omitempty"` // Ensemble models (provider:model) whose extractions of the page included it
+}
+
+// ScanMismatch is an LLM candidate the number scanner could not confirm
+type ScanMismatch struct {
+	Candidate CandidateStatistic `json:"candidate"`
+	Reason    string             `json:"reason"`
+	Discarded bool               `json:"discarded"` // Dropped from the candidates rather than flagged
+}
+
+// ToStatistic converts a candidate into a statistic with the given verification status
+func (c *CandidateStatistic) ToStatistic(verified bool) *Statistic {
+	return &Statistic{
+		Name:          c.Name,
+		Value:         c.Value,
+		ValueText:     c.ValueText,
+		ValueKind:     c.ValueKind,
+		ValueMin:      c.ValueMin,
+		ValueMax:      c.ValueMax,
+		Unit:          c.Unit,
+		Period:        c.Period,
+		Geography:     c.Geography,
+		Population:    c.Population,
+		Methodology:   c.Methodology,
+		Relevance:     c.Relevance,
+		Source:        c.Source,
+		SourceURL:     c.SourceURL,
+		Publisher:     c.Publisher,
+		Author:        c.Author,
+		PublishedDate: c.PublishedDate,
+		ModifiedDate:  c.ModifiedDate,
+		Page:          c.Page,
+		Table:         c.Table,
+		Excerpt:       c.Excerpt,
+		PromptID:      c.PromptID,
+		PromptVariant: c.PromptVariant,
+		PromptVersion: c.PromptVersion,
+		AgreedModels:  c.AgreedModels,
+		Verified:      verified,
+		DateFound:     time.Now(),
+	}
+}
+
+// CitationURL returns the source URL, pointing at the page for PDF sources
+// (#page=N, which browser PDF viewers open at)
+func (s *Statistic) CitationURL() string {
+	if s.Page <= 0 {
+		return s.SourceURL
+	}
+	return fmt.Sprintf("%s#page=%d", strings.SplitN(s.SourceURL, "#", 2)[0], s.Page)
 }
 
 // VerificationResult represents the result of verifying a statistic
@@ -37,13 +132,24 @@ type ResearchRequest struct {
 	MinStatistics int    `json:"min_statistics"` // Minimum number of statistics to find
 	MaxStatistics int    `json:"max_statistics"` // Maximum number of statistics to find
 	ReputableOnly bool   `json:"reputable_only"` // Only search reputable sources
+
+	// Per-request reputation overrides (domains match themselves and their subdomains)
+	AllowDomains []string `json:"allow_domains,omitempty"` // Treat these domains as reputable
+	DenyDomains  []string `json:"deny_domains,omitempty"`  // Never use these domains
+
+	SearchOptions
 }
 
 // ResearchResponse represents the response from research agent
 type ResearchResponse struct {
-	Topic      string               `json:"topic"`
-	Candidates []CandidateStatistic `json:"candidates"`
-	Timestamp  time.Time            `json:"timestamp"`
+	Topic         string         `json:"topic"`
+	SearchResults []SearchResult `json:"search_results,omitempty"` // Ranked sources (/v2/research)
+	// Deprecated: Candidates holds placeholder candidates built from search results.
+	// It is only populated by the legacy /research endpoint (RESEARCH_LEGACY_API=true).
+	Candidates   []CandidateStatistic `json:"candidates,omitempty"`
+	QueriesRun   int                  `json:"queries_run,omitempty"`   // Search queries planned for the topic
+	PagesFetched int                  `json:"pages_fetched,omitempty"` // Result pages fetched across all queries
+	Timestamp    time.Time            `json:"timestamp"`
 }
 
 // VerificationRequest represents a request to verify statistics
@@ -65,6 +171,21 @@ type OrchestrationRequest struct {
 	MinVerifiedStats int    `json:"min_verified_stats"` // Minimum verified statistics required
 	MaxCandidates    int    `json:"max_candidates"`     // Maximum candidates to research
 	ReputableOnly    bool   `json:"reputable_only"`
+
+	// Per-request reputation overrides (domains match themselves and their subdomains)
+	AllowDomains []string `json:"allow_domains,omitempty"` // Treat these domains as reputable
+	DenyDomains  []string `json:"deny_domains,omitempty"`  // Never use these domains
+
+	// Scope filters (statistics lacking the scope a filter needs are dropped)
+	Countries  []string `json:"countries,omitempty"`   // ISO 3166-1 alpha-2 (or region) codes to keep (e.g., ["US"])
+	PeriodFrom int      `json:"period_from,omitempty"` // Keep statistics whose reference period ends in or after this year
+	PeriodTo   int      `json:"period_to,omitempty"`   // Keep statistics whose reference period starts in or before this year
+
+	MinRelevance float64 `json:"min_relevance,omitempty"` // Drop candidates scoring below this relevance to the topic (0-1; 0 = keep all)
+
+	PromptVariant string `json:"prompt_variant,omitempty"` // Extraction prompt variant (e.g., for A/B comparisons; PROMPT_VARIANT if empty)
+
+	SearchOptions
 }
 
 // OrchestrationResponse represents the final response
@@ -74,6 +195,8 @@ type OrchestrationResponse struct {
 	TotalCandidates int         `json:"total_candidates"`
 	VerifiedCount   int         `json:"verified_count"`
 	FailedCount     int         `json:"failed_count"`
+	FilteredCount   int         `json:"filtered_count,omitempty"` // Candidates dropped by scope and relevance filters
+	MergedCount     int         `json:"merged_count,omitempty"`   // Verified statistics merged into another as corroborating sources
 	Timestamp       time.Time   `json:"timestamp"`
 	Partial         bool        `json:"partial"`                   // True if target not met
 	TargetCount     int         `json:"target_count"`              // The minimum requested
@@ -82,11 +205,14 @@ type OrchestrationResponse struct {
 
 // SearchResult represents a source URL from research agent
 type SearchResult struct {
-	URL      string `json:"url"`
-	Title    string `json:"title"`
-	Snippet  string `json:"snippet"`
-	Domain   string `json:"domain"`
-	Position int    `json:"position,omitempty"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	Snippet       string   `json:"snippet"`
+	Domain        string   `json:"domain"`
+	Position      int      `json:"position,omitempty"`
+	Provider      string   `json:"provider,omitempty"`       // Search provider(s) that returned the result
+	Queries       []string `json:"queries,omitempty"`        // Search queries that found the result
+	PublishedDate string   `json:"published_date,omitempty"` // Publication date (YYYY-MM-DD) reported by the search provider
 }
 
 // SynthesisRequest is the request to synthesis agent
@@ -95,12 +221,30 @@ type SynthesisRequest struct {
 	SearchResults []SearchResult `json:"search_results"`
 	MinStatistics int            `json:"min_statistics"`
 	MaxStatistics int            `json:"max_statistics"`
+	MinRelevance  float64        `json:"min_relevance,omitempty"`  // Drop candidates scoring below this relevance (0 = keep all)
+	PromptVariant string         `json:"prompt_variant,omitempty"` // Extraction prompt variant (PROMPT_VARIANT if empty)
+	AllowDomains  []string       `json:"allow_domains,omitempty"`  // Per-request reputation overrides, as in OrchestrationRequest
+	DenyDomains   []string       `json:"deny_domains,omitempty"`   // Sources on these domains are not fetched
+	// Sources whose page declares a publication date outside these bounds
+	// (YYYY-MM-DD, as in SearchOptions) are skipped
+	PublishedAfter  string `json:"published_after,omitempty"`
+	PublishedBefore string `json:"published_before,omitempty"`
+}
+
+// DateRange parses the publication date bounds, as SearchOptions.DateRange
+func (r *SynthesisRequest) DateRange() (after, before time.Time, err error) {
+	opts := SearchOptions{PublishedAfter: r.PublishedAfter, PublishedBefore: r.PublishedBefore}
+	return opts.DateRange()
 }
 
 // SynthesisResponse is the response from synthesis agent
 type SynthesisResponse struct {
-	Topic           string               `json:"topic"`
-	Candidates      []CandidateStatistic `json:"candidates"`
-	SourcesAnalyzed int                  `json:"sources_analyzed"`
-	Timestamp       time.Time            `json:"timestamp"`
+	Topic             string               `json:"topic"`
+	Candidates        []CandidateStatistic `json:"candidates"`
+	SourcesAnalyzed   int                  `json:"sources_analyzed"`
+	DuplicatesSkipped int                  `json:"duplicates_skipped,omitempty"` // Sources skipped as duplicates of an earlier source
+	ScanMismatches    []ScanMismatch       `json:"scan_mismatches,omitempty"`    // Candidates the number scanner could not confirm
+	RelevanceFiltered int                  `json:"relevance_filtered,omitempty"` // Candidates dropped for scoring below MinRelevance
+	DateFiltered      int                  `json:"date_filtered,omitempty"`      // Sources skipped for a publication date outside the request's range
+	Timestamp         time.Time            `json:"timestamp"`
 }
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValueKind describes how the numerical value of a statistic should be read
+type ValueKind string
+
+const (
+	ValueKindPoint       ValueKind = "point"       // A single stated value (e.g., "45%")
+	ValueKindRange       ValueKind = "range"       // A span with both ends stated (e.g., "79-96% of firms")
+	ValueKindLowerBound  ValueKind = "lower_bound" // At least the value (e.g., "at least 2 million users")
+	ValueKindUpperBound  ValueKind = "upper_bound" // At most the value (e.g., "fewer than 500 cases")
+	ValueKindApproximate ValueKind = "approximate" // Roughly the value (e.g., "about 1.1°C")
+)
+
+// ParseValueKind maps a free-form kind (as returned by an LLM) to a ValueKind.
+// Unknown or empty kinds are treated as point values.
+func ParseValueKind(s string) ValueKind {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "range":
+		return ValueKindRange
+	case "lower_bound", "lower bound", "min", "minimum", "at least":
+		return ValueKindLowerBound
+	case "upper_bound", "upper bound", "max", "maximum", "at most":
+		return ValueKindUpperBound
+	case "approximate", "approx", "about":
+		return ValueKindApproximate
+	default:
+		return ValueKindPoint
+	}
+}
+
+// FormatValue renders a value of the given kind together with its unit for display
+func FormatValue(kind ValueKind, value float64, valueMin, valueMax *float64, unit string) string {
+	var text string
+	switch kind {
+	case ValueKindRange:
+		low, high := value, value
+		if valueMin != nil {
+			low = *valueMin
+		}
+		if valueMax != nil {
+			high = *valueMax
+		}
+		text = formatNumber(low) + "-" + formatNumber(high)
+	case ValueKindLowerBound:
+		text = "at least " + formatNumber(value)
+	case ValueKindUpperBound:
+		text = "at most " + formatNumber(value)
+	case ValueKindApproximate:
+		text = "approximately " + formatNumber(value)
+	default:
+		text = formatNumber(value)
+	}
+	if unit != "" {
+		text += " " + unit
+	}
+	return text
+}
+
+// DisplayValue returns the human-readable value of the statistic including its unit
+func (s *Statistic) DisplayValue() string {
+	return FormatValue(s.ValueKind, s.Value, s.ValueMin, s.ValueMax, s.Unit)
+}
+
+// formatNumber formats a number without trailing zeros
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// numberPattern matches numbers written in text: an optional sign, thousands
+// separators, decimals and a magnitude word ("-3.5", "1,200", "1.2 million")
+var numberPattern = regexp.MustCompile(`(?i)([-−]?)(\d[\d,]*(?:\.\d+)?)(?:\s?(thousand|million|billion|trillion|bn|mn)\b)?`)
+
+// rangeSeparator matches the text between the two ends of a range ("1-2 million")
+var rangeSeparator = regexp.MustCompile(`^\s?(?:-|–|—|−|to)\s?$`)
+
+// magnitudes are the multipliers of magnitude words
+var magnitudes = map[string]float64{
+	"thousand": 1e3, "million": 1e6, "mn": 1e6, "billion": 1e9, "bn": 1e9, "trillion": 1e12,
+}
+
+// Magnitude returns the multiplier of a magnitude word ("million", "bn"), or 0
+// if word is not one
+func Magnitude(word string) float64 {
+	return magnitudes[strings.ToLower(word)]
+}
+
+// writtenNumber is a number found in text
+type writtenNumber struct {
+	value     float64 // As written, with its sign
+	magnitude float64 // Multiplier of the magnitude word after it, 1 if none
+}
+
+// scanNumbers returns the numbers written in text. A minus sign counts only at
+// the start of a number, not between two numbers ("79-96" is a range), and the
+// magnitude of a range's upper end applies to its lower end ("1-2 million").
+func scanNumbers(text string) []writtenNumber {
+	matches := numberPattern.FindAllStringSubmatchIndex(text, -1)
+	numbers := make([]writtenNumber, 0, len(matches))
+	ends := make([]int, 0, len(matches)) // End of each number's digits
+	starts := make([]int, 0, len(matches))
+	for _, m := range matches {
+		digits := strings.TrimRight(strings.ReplaceAll(text[m[4]:m[5]], ",", ""), ".")
+		n, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			continue
+		}
+		if m[3] > m[2] && !afterWordOrDigit(text, m[2]) {
+			n = -n
+		}
+		mult := 1.0
+		if m[6] >= 0 {
+			mult = Magnitude(text[m[6]:m[7]])
+		}
+		numbers = append(numbers, writtenNumber{value: n, magnitude: mult})
+		starts = append(starts, m[4])
+		ends = append(ends, m[5])
+	}
+	for i := len(numbers) - 2; i >= 0; i-- {
+		if numbers[i].magnitude == 1 && numbers[i+1].magnitude != 1 &&
+			rangeSeparator.MatchString(text[ends[i]:starts[i+1]]) {
+			numbers[i].magnitude = numbers[i+1].magnitude
+		}
+	}
+	return numbers
+}
+
+// afterWordOrDigit reports whether the character before offset i is a letter or
+// digit, making a dash at i a hyphen or range separator rather than a sign
+func afterWordOrDigit(text string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ExtractNumbers returns every number written in text as a full value: thousands
+// separators removed, signs kept and magnitude words applied ("1.2 million" is
+// 1200000)
+func ExtractNumbers(text string) []float64 {
+	found := scanNumbers(text)
+	numbers := make([]float64, len(found))
+	for i, n := range found {
+		numbers[i] = n.value * n.magnitude
+	}
+	return numbers
+}
+
+// ParseValueText returns the first number in a literal value text such as
+// "1.2 million" (1200000), "331,449,281", "-0.4%" or "79-96%" as a full value.
+// For ranges this is the lower end.
+func ParseValueText(text string) (float64, bool) {
+	numbers := ExtractNumbers(text)
+	if len(numbers) == 0 {
+		return 0, false
+	}
+	return numbers[0], true
+}
+
+// ValueInText reports whether a number written in text equals value, either as a
+// full value or scaled by a magnitude context states for a whole table or column
+// (a header "in millions" above "1.2"). A magnitude word elsewhere in context
+// ("3 million people") does not scale the numbers of text.
+func ValueInText(value float64, text, context string) bool {
+	found := scanNumbers(text)
+	for _, n := range found {
+		if ValuesEqual(n.value*n.magnitude, value) {
+			return true
+		}
+	}
+	for _, m := range headerMagnitude.FindAllStringSubmatch(context, -1) {
+		mult := Magnitude(m[1] + m[2])
+		for _, n := range found {
+			if n.magnitude == 1 && ValuesEqual(n.value*mult, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headerMagnitude matches a magnitude as table headers state it for their
+// numbers: in parentheses ("(millions)", "(USD bn)", "(in thousands of people)")
+// or after "in" ("in millions", "in USD billion")
+var headerMagnitude = regexp.MustCompile(`(?i)\(\s*(?:in\s+)?(?:(?:usd|us\$|eur|gbp|jpy|cny|[$€£¥])\s*)?(thousand|million|billion|trillion|bn|mn)s?\b[^()]*\)|\bin\s+(?:(?:usd|us\$|eur|gbp|jpy|cny|[$€£¥])\s*)?(thousand|million|billion|trillion|bn|mn)s?\b`)
+
+// unitMagnitude matches a magnitude word leading a unit ("million", "millions of people")
+var unitMagnitude = regexp.MustCompile(`(?i)^(thousand|million|billion|trillion|bn|mn)s?\b(?:\s+of\b)?\s*`)
+
+// NormalizeValue makes Value (and a range's ends) the full number: the
+// magnitude written in the value text ("1.2 million") or left in the unit
+// ("million people", as table headers state it) is applied, and dropped from
+// the unit. Numbers already given in full are left alone.
+func (c *CandidateStatistic) NormalizeValue() {
+	mult := 1.0
+	var literal []float64 // Numbers of the value text as written, before scaling
+	for _, n := range scanNumbers(c.ValueText) {
+		literal = append(literal, n.value)
+		if mult == 1 {
+			mult = n.magnitude
+		}
+	}
+	if m := unitMagnitude.FindStringSubmatch(c.Unit); m != nil {
+		if mult == 1 {
+			mult = Magnitude(m[1])
+		}
+		c.Unit = strings.TrimSpace(c.Unit[len(m[0]):])
+	}
+	if mult == 1 {
+		return
+	}
+
+	scale := func(v float64) float64 {
+		if len(literal) == 0 {
+			return v * mult
+		}
+		for _, n := range literal {
+			if ValuesEqual(n, v) {
+				return v * mult
+			}
+		}
+		return v
+	}
+	c.Value = scale(c.Value)
+	if c.ValueMin != nil {
+		v := scale(*c.ValueMin)
+		c.ValueMin = &v
+	}
+	if c.ValueMax != nil {
+		v := scale(*c.ValueMax)
+		c.ValueMax = &v
+	}
+}
+
+// ValuesEqual compares two normalized values, tolerating floating point noise
+func ValuesEqual(a, b float64) bool {
+	diff := math.Abs(a - b)
+	return diff <= 1e-9 || diff <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
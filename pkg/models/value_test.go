@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtractNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", "[]"},
+		{"no numbers here", "[]"},
+		{"331,449,281 people", "[3.31449281e+08]"},
+		{"1.2 million users", "[1.2e+06]"},
+		{"$3.5bn in 2023", "[3.5e+09 2023]"},
+		{"fell -0.4% and −2.1%", "[-0.4 -2.1]"},
+		{"79-96% of firms", "[79 96]"},
+		{"1-2 million people", "[1e+06 2e+06]"},
+		{"between 3 to 5 billion", "[3e+09 5e+09]"},
+		{"COVID-19 cases", "[19]"},
+		{"rose 12. Then", "[12]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := fmt.Sprint(ExtractNumbers(tt.text)); got != tt.want {
+				t.Errorf("ExtractNumbers(%q) = %s, want %s", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueInText(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         float64
+		text, context string
+		want          bool
+	}{
+		{"full value", 1.2e6, "1.2 million", "", true},
+		{"separators", 1200000, "1,200,000 users", "", true},
+		{"magnitude in context", 1.2e6, "1.2", "Sales (in millions)", true},
+		{"parenthesized magnitude", 3.5e9, "3.5", "Region / Revenue (USD bn) / 2023: 3.5", true},
+		{"in magnitude", 2e3, "2", "Figures in thousands. Cases: 2", true},
+		{"stray magnitude in context", 14e6, "14", "14 vehicles were sold, serving 3 million people", false},
+		{"magnitude of another number", 14e6, "14", "14 of the 20 million respondents", false},
+		{"magnitude in text only", 1.2, "1.2 million", "", false},
+		{"missing", 15, "14 million", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValueInText(tt.value, tt.text, tt.context); got != tt.want {
+				t.Errorf("ValueInText(%v, %q, %q) = %v, want %v", tt.value, tt.text, tt.context, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name string
+		in   CandidateStatistic
+		want string // Value, range ends and unit after normalizing
+	}{
+		{"magnitude in value text", CandidateStatistic{Value: 1.2, ValueText: "1.2 million", Unit: "users"}, "1.2e+06 users"},
+		{"already full", CandidateStatistic{Value: 1.2e6, ValueText: "1.2 million", Unit: "users"}, "1.2e+06 users"},
+		{"magnitude in unit", CandidateStatistic{Value: 14, Unit: "million cars"}, "1.4e+07 cars"},
+		{"magnitude of unit", CandidateStatistic{Value: 3, Unit: "millions of people"}, "3e+06 people"},
+		{"range", CandidateStatistic{Value: 1, ValueKind: ValueKindRange, ValueMin: f(1), ValueMax: f(2), ValueText: "1-2 billion"}, "1e+09 [1e+09 2e+09]"},
+		{"no magnitude", CandidateStatistic{Value: 45, ValueText: "45%", Unit: "%"}, "45 %"},
+		{"negative", CandidateStatistic{Value: -2.5, ValueText: "-2.5 billion", Unit: "$"}, "-2.5e+09 $"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.in
+			c.NormalizeValue()
+			got := fmt.Sprint(c.Value)
+			if c.ValueMin != nil && c.ValueMax != nil {
+				got += fmt.Sprintf(" [%v %v]", *c.ValueMin, *c.ValueMax)
+			}
+			if c.Unit != "" {
+				got += " " + c.Unit
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	lo, hi := 79.0, 96.0
+	tests := []struct {
+		kind  ValueKind
+		value float64
+		unit  string
+		want  string
+	}{
+		{ValueKindPoint, 45.50, "%", "45.5 %"},
+		{ValueKindRange, 79, "%", "79-96 %"},
+		{ValueKindLowerBound, 2e6, "users", "at least 2000000 users"},
+		{ValueKindUpperBound, 500, "", "at most 500"},
+		{ParseValueKind("About"), 1.1, "°C", "approximately 1.1 °C"},
+		{ParseValueKind("unknown"), 3, "", "3"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.kind, tt.value, &lo, &hi, tt.unit); got != tt.want {
+			t.Errorf("FormatValue(%s, %v) = %q, want %q", tt.kind, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Package numscan finds the numbers written in page text without an LLM, so
+// statistics an LLM extracts can be cross-checked against what the page says.
+package numscan
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grokify/stats-agent-team/pkg/models"
+)
+
+// Kind is what a number measures, judging by its symbols and words
+type Kind string
+
+const (
+	KindNumber   Kind = "number"   // A plain count or measurement
+	KindPercent  Kind = "percent"  // "45%", "45 percent", "3 percentage points"
+	KindCurrency Kind = "currency" // "$1.2 billion", "€300"
+)
+
+// Mention is a number found in text, with the symbols and words that qualify it
+type Mention struct {
+	Text      string    // Literal span, e.g., "$1.2 billion" or "79-96%"
+	Values    []float64 // The numbers as written, with their sign (one, or both ends of a range)
+	Kind      Kind
+	Magnitude string // "thousand", "million", "billion" or "trillion", if stated
+	Start     int    // Byte offset of the span in the text
+	End       int
+}
+
+// mentionPattern matches a number with an optional sign, currency prefix, range
+// end and unit or magnitude suffix
+var mentionPattern = regexp.MustCompile(`(?i)` +
+	`(?P<sign>[-−])?(?P<currency>US\$|USD\s?|[$€£¥])?(?P<sign1>[-−])?` +
+	`(?P<num>\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?|\.\d+)` +
+	`(?:\s?(?:-|–|—|to)\s?(?:US\$|[$€£¥])?(?P<sign2>[-−])?(?P<num2>\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?))?` +
+	`(?:\s?(?P<suffix>%|percent(?:age points?)?|per cent|pct\b|thousand\b|million\b|billion\b|trillion\b|bn\b|mn\b))?`)
+
+// magnitudes maps magnitude words to their names
+var magnitudes = map[string]string{
+	"thousand": "thousand", "million": "million", "mn": "million",
+	"billion": "billion", "bn": "billion", "trillion": "trillion",
+}
+
+// Scan returns the numbers mentioned in text, in order. A minus sign before the
+// number or its currency symbol makes it negative ("-3.2%", "−$5 billion",
+// "$-5"), unless it follows a word or number. Bare years (1800-2100 with no
+// unit, currency or thousands separator), years in year ranges and numbers that
+// are part of names or codes ("COVID-19", "H1N1", "ISO 3166") are skipped.
+func Scan(text string) []Mention {
+	var mentions []Mention
+	names := mentionPattern.SubexpNames()
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		groups := map[string]string{}
+		for i, name := range names {
+			if name != "" && m[2*i] >= 0 {
+				groups[name] = text[m[2*i]:m[2*i+1]]
+			}
+		}
+		start, end := m[0], m[1]
+		if partOfName(text, start) || followedByLetterOrDigit(text, end, groups["suffix"] == "") {
+			continue
+		}
+
+		mention := Mention{Text: strings.TrimSpace(text[start:end]), Kind: KindNumber, Start: start, End: end}
+		for _, key := range []string{"num", "num2"} {
+			if s, ok := groups[key]; ok {
+				n, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+				if err != nil {
+					continue
+				}
+				negative := groups["sign2"] != ""
+				if key == "num" {
+					negative = groups["sign"] != "" || groups["sign1"] != ""
+				}
+				if negative {
+					n = -n
+				}
+				mention.Values = append(mention.Values, n)
+			}
+		}
+		if len(mention.Values) == 0 {
+			continue
+		}
+
+		suffix := strings.ToLower(groups["suffix"])
+		switch {
+		case groups["currency"] != "":
+			mention.Kind = KindCurrency
+		case suffix == "%" || strings.HasPrefix(suffix, "percent") || suffix == "per cent" || suffix == "pct":
+			mention.Kind = KindPercent
+		}
+		mention.Magnitude = magnitudes[suffix]
+
+		if mention.Kind == KindNumber && mention.Magnitude == "" && isYears(groups["num"], groups["num2"]) {
+			continue
+		}
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// FullValues returns the mention's values with its magnitude applied
+func (m Mention) FullValues() []float64 {
+	mult := models.Magnitude(m.Magnitude)
+	if mult == 0 {
+		return m.Values
+	}
+	full := make([]float64, len(m.Values))
+	for i, v := range m.Values {
+		full[i] = v * mult
+	}
+	return full
+}
+
+// isYears reports whether the numbers of a mention are a bare year or year range
+func isYears(nums ...string) bool {
+	for _, s := range nums {
+		if s == "" {
+			continue
+		}
+		if len(s) != 4 {
+			return false
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1800 || n > 2100 {
+			return false
+		}
+	}
+	return true
+}
+
+// partOfName reports whether the number at start continues a word, as in
+// "COVID-19", "H1N1" or "A4"
+func partOfName(text string, start int) bool {
+	if start == 0 {
+		return false
+	}
+	r, size := utf8.DecodeLastRuneInString(text[:start])
+	if r == '-' {
+		r, _ = utf8.DecodeLastRuneInString(text[:start-size])
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// followedByLetterOrDigit reports whether the span at end runs into a word, as in
+// "19th" or "3D". Spans ending in a unit word already end at a word boundary.
+func followedByLetterOrDigit(text string, end int, check bool) bool {
+	if !check || end >= len(text) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text[end:])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Index is page text prepared for cross-checking extracted statistics
+type Index struct {
+	folded   string // Text with whitespace collapsed and ASCII letters lowercased
+	offsets  []int  // Byte offset in the original text of each byte of folded
+	mentions []Mention
+}
+
+// NewIndex scans text for numbers and indexes it for excerpt lookups
+func NewIndex(text string) *Index {
+	folded, offsets := fold(text)
+	return &Index{folded: folded, offsets: offsets, mentions: Scan(text)}
+}
+
+// fold collapses whitespace runs to one space and lowercases ASCII letters,
+// returning the original byte offset of each byte of the result. Byte lengths
+// are preserved, so offsets stay exact for any UTF-8 text.
+func fold(text string) (string, []int) {
+	var b strings.Builder
+	var offsets []int
+	space := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v' {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			offsets = append(offsets, i)
+			space = false
+		}
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
+		offsets = append(offsets, i)
+	}
+	return b.String(), offsets
+}
+
+// Mentions returns the numbers found in the text
+func (ix *Index) Mentions() []Mention {
+	return ix.mentions
+}
+
+// percentUnit and currencyUnit recognize the units of percentages and amounts of money
+var (
+	percentUnit  = regexp.MustCompile(`(?i)%|\b(?:percent|per cent|percentage|pct)\b`)
+	currencyUnit = regexp.MustCompile(`(?i)[$€£¥]|\b(?:usd|eur|gbp|jpy|cny|inr|dollars?|euros?|pounds sterling|yen|yuan)\b`)
+)
+
+// UnitKind returns the kind of number a statistic's unit describes: "%" or
+// "percent of adults" is a percentage, "USD" or "dollars" a currency amount
+func UnitKind(unit string) Kind {
+	switch {
+	case percentUnit.MatchString(unit):
+		return KindPercent
+	case currencyUnit.MatchString(unit):
+		return KindCurrency
+	}
+	return KindNumber
+}
+
+// Confirm checks that excerpt appears in the text (ignoring case and whitespace)
+// and that the scanner found a number of the given kind equal to each of values
+// inside it. Values are full values ("1.2 million" is 1200000, see
+// models.CandidateStatistic.NormalizeValue), so the magnitude must match too: a
+// "45%" or "45" in the excerpt doesn't confirm 45 million. Percentages only
+// confirm percent values and the reverse; numbers and currency amounts confirm
+// each other, as pages often write amounts without a symbol ("45 million
+// dollars"). It returns nil, or an error describing the mismatch.
+func (ix *Index) Confirm(excerpt string, kind Kind, values ...float64) error {
+	want, _ := fold(excerpt)
+	if want == "" {
+		return errors.New("empty excerpt")
+	}
+
+	found := false
+	for from := 0; from <= len(ix.folded); {
+		i := strings.Index(ix.folded[from:], want)
+		if i < 0 {
+			break
+		}
+		found = true
+		start := ix.offsets[from+i]
+		end := ix.offsets[from+i+len(want)-1] + 1
+		if ix.hasValues(start, end, kind, values) {
+			return nil
+		}
+		from += i + 1
+	}
+	if !found {
+		return errors.New("excerpt not found in page text")
+	}
+	return fmt.Errorf("%s value %s not found by the number scanner in the excerpt", kind, formatValues(values))
+}
+
+// hasValues reports whether every value equals a number of a compatible kind
+// mentioned in text[start:end], with the mention's magnitude applied
+func (ix *Index) hasValues(start, end int, kind Kind, values []float64) bool {
+	for _, v := range values {
+		ok := false
+		for _, m := range ix.mentions {
+			if m.End <= start || m.Start >= end || (m.Kind == KindPercent) != (kind == KindPercent) {
+				continue
+			}
+			for _, n := range m.FullValues() {
+				if models.ValuesEqual(n, v) {
+					ok = true
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func formatValues(values []float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,123 @@
+package numscan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string // Text:Kind:Values:Magnitude of each mention
+	}{
+		{"plain number", "About 1,234 people attended.", []string{"1,234:number:[1234]:"}},
+		{"percent", "Turnout was 45% this year.", []string{"45%:percent:[45]:"}},
+		{"percent word", "It rose 3 percentage points.", []string{"3 percentage points:percent:[3]:"}},
+		{"currency with magnitude", "Revenue hit $1.2 billion.", []string{"$1.2 billion:currency:[1.2]:billion"}},
+		{"currency code", "It cost USD 300 per unit.", []string{"USD 300:currency:[300]:"}},
+		{"range", "Between 79-96% agreed.", []string{"79-96%:percent:[79 96]:"}},
+		{"range with to", "from 10 to 20 million", []string{"10 to 20 million:number:[10 20]:million"}},
+		{"negative", "GDP fell -3.2% in 2020.", []string{"-3.2%:percent:[-3.2]:"}},
+		{"unicode minus", "a change of −0.5 points", []string{"−0.5:number:[-0.5]:"}},
+		{"negative currency", "a loss of -$5 billion", []string{"-$5 billion:currency:[-5]:billion"}},
+		{"sign after currency", "net income of $-12 million", []string{"$-12 million:currency:[-12]:million"}},
+		{"negative range", "between -5 and -3", []string{"-5:number:[-5]:", "-3:number:[-3]:"}},
+		{"bare year", "In 2023 sales grew.", nil},
+		{"year range", "During 2019-2021 nothing changed.", nil},
+		{"name with number", "COVID-19 and H1N1 cases", nil},
+		{"ordinal", "the 19th century", nil},
+		{"year with unit kept", "2023 million", []string{"2023 million:number:[2023]:million"}},
+		{"short magnitude", "€3bn in aid", []string{"€3bn:currency:[3]:billion"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range Scan(tt.text) {
+				got = append(got, fmt.Sprintf("%s:%s:%v:%s", m.Text, m.Kind, m.Values, m.Magnitude))
+				if tt.text[m.Start:m.End] != m.Text && strings.TrimSpace(tt.text[m.Start:m.End]) != m.Text {
+					t.Errorf("span %d-%d is %q, not %q", m.Start, m.End, tt.text[m.Start:m.End], m.Text)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Scan(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFullValues(t *testing.T) {
+	m := Scan("between 1.5 and 2 billion")[1]
+	if got := fmt.Sprint(m.FullValues()); got != "[2e+09]" {
+		t.Errorf("FullValues = %s", got)
+	}
+	m = Scan("$1.2-1.5 million")[0]
+	if got := fmt.Sprint(m.FullValues()); got != "[1.2e+06 1.5e+06]" {
+		t.Errorf("FullValues = %s", got)
+	}
+}
+
+func TestUnitKind(t *testing.T) {
+	tests := map[string]Kind{
+		"%":                 KindPercent,
+		"percent of adults": KindPercent,
+		"percentage points": KindPercent,
+		"USD":               KindCurrency,
+		"US dollars":        KindCurrency,
+		"€":                 KindCurrency,
+		"euros per year":    KindCurrency,
+		"people":            KindNumber,
+		"":                  KindNumber,
+		"pounds":            KindNumber, // Could be weight
+		"tonnes of CO2":     KindNumber,
+	}
+	for unit, want := range tests {
+		if got := UnitKind(unit); got != want {
+			t.Errorf("UnitKind(%q) = %s, want %s", unit, got, want)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	text := `Global EV sales reached 14 million in 2023, up 35% from the year before.
+Revenue was $1.2 billion, while 45% of buyers were first-time owners.
+Prices fell −8% on average; margins ranged from 10-12 percent.
+The fleet totals 45,000,000 vehicles.`
+	ix := NewIndex(text)
+
+	tests := []struct {
+		name    string
+		excerpt string
+		kind    Kind
+		values  []float64
+		wantErr string
+	}{
+		{"full value with magnitude", "EV sales reached 14 million in 2023", KindNumber, []float64{14e6}, ""},
+		{"whitespace and case", "global ev SALES   reached 14 million", KindNumber, []float64{14e6}, ""},
+		{"percent", "up 35% from the year before", KindPercent, []float64{35}, ""},
+		{"currency", "Revenue was $1.2 billion", KindCurrency, []float64{1.2e9}, ""},
+		{"currency unit, plain number", "The fleet totals 45,000,000 vehicles", KindCurrency, []float64{45e6}, ""},
+		{"negative", "Prices fell −8% on average", KindPercent, []float64{-8}, ""},
+		{"range", "margins ranged from 10-12 percent", KindPercent, []float64{10, 12}, ""},
+		{"magnitude missing", "EV sales reached 14 million in 2023", KindNumber, []float64{14}, "not found by the number scanner"},
+		{"percent doesn't confirm millions", "45% of buyers were first-time owners", KindNumber, []float64{45e6}, "not found by the number scanner"},
+		{"percent doesn't confirm a count", "45% of buyers were first-time owners", KindNumber, []float64{45}, "number value 45 not found"},
+		{"count doesn't confirm a percent", "The fleet totals 45,000,000 vehicles", KindPercent, []float64{45e6}, "percent value"},
+		{"sign must match", "Prices fell −8% on average", KindPercent, []float64{8}, "not found by the number scanner"},
+		{"range end missing", "margins ranged from 10-12 percent", KindPercent, []float64{10, 15}, "10-15"},
+		{"excerpt not on page", "EV sales reached 15 million", KindNumber, []float64{15e6}, "excerpt not found"},
+		{"empty excerpt", "  ", KindNumber, []float64{1}, "empty excerpt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ix.Confirm(tt.excerpt, tt.kind, tt.values...)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Confirm: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Confirm error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,20 +13,29 @@ import (
 	"github.com/grokify/stats-agent-team/pkg/config"
 	"github.com/grokify/stats-agent-team/pkg/httpclient"
 	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/reputation"
 )
 
 // EinoOrchestrationAgent uses Eino framework for deterministic orchestration
 type EinoOrchestrationAgent struct {
-	cfg    *config.Config
-	client *http.Client
-	graph  *compose.Graph[*models.OrchestrationRequest, *models.OrchestrationResponse]
+	cfg        *config.Config
+	client     *http.Client
+	graph      *compose.Graph[*models.OrchestrationRequest, *models.OrchestrationResponse]
+	reputation *reputation.Registry
 }
 
 // NewEinoOrchestrationAgent creates a new Eino-based orchestration agent
 func NewEinoOrchestrationAgent(cfg *config.Config) *EinoOrchestrationAgent {
+	registry, err := reputation.LoadFromConfig(cfg)
+	if err != nil {
+		log.Printf("[Eino] Warning: %v (using default reputation registry)", err)
+		registry = reputation.Default()
+	}
+
 	oa := &EinoOrchestrationAgent{
-		cfg:    cfg,
-		client: &http.Client{Timeout: 60 * time.Second},
+		cfg:        cfg,
+		client:     &http.Client{Timeout: 60 * time.Second},
+		reputation: registry,
 	}
 
 	// Build the deterministic workflow graph
@@ -80,6 +89,9 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 			MinStatistics: req.MinVerifiedStats,
 			MaxStatistics: req.MaxCandidates,
 			ReputableOnly: req.ReputableOnly,
+			AllowDomains:  req.AllowDomains,
+			DenyDomains:   req.DenyDomains,
+			SearchOptions: req.SearchOptions,
 		}
 
 		resp, err := oa.callResearchAgent(ctx, researchReq)
@@ -87,16 +99,7 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 			return nil, fmt.Errorf("research failed: %w", err)
 		}
 
-		// Convert candidates to search results
-		searchResults := make([]models.SearchResult, 0, len(resp.Candidates))
-		for _, cand := range resp.Candidates {
-			searchResults = append(searchResults, models.SearchResult{
-				URL:     cand.SourceURL,
-				Title:   cand.Name,
-				Snippet: cand.Excerpt,
-				Domain:  cand.Source,
-			})
-		}
+		searchResults := resp.SearchResults
 
 		log.Printf("[Eino] Research found %d sources", len(searchResults))
 
@@ -118,6 +121,13 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 			SearchResults: state.SearchResults,
 			MinStatistics: state.Request.MinVerifiedStats,
 			MaxStatistics: state.Request.MaxCandidates,
+			MinRelevance:  state.Request.MinRelevance,
+			PromptVariant: state.Request.PromptVariant,
+			AllowDomains:  state.Request.AllowDomains,
+			DenyDomains:   state.Request.DenyDomains,
+			// Pages can declare dates the search results lacked
+			PublishedAfter:  state.Request.PublishedAfter,
+			PublishedBefore: state.Request.PublishedBefore,
 		}
 
 		resp, err := oa.callSynthesisAgent(ctx, synthesisReq)
@@ -131,6 +141,7 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 			Request:       state.Request,
 			SearchResults: state.SearchResults,
 			Candidates:    resp.Candidates,
+			Irrelevant:    resp.RelevanceFiltered,
 		}, nil
 	})
 	if err := g.AddLambdaNode(nodeSynthesis, synthesisLambda); err != nil {
@@ -139,10 +150,17 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 
 	// 4. Verification Node - calls verification agent
 	verificationLambda := compose.InvokableLambda(func(ctx context.Context, state *SynthesisState) (*VerificationState, error) {
-		log.Printf("[Eino] Verifying %d candidates", len(state.Candidates))
+		// Drop candidates outside the requested period and geography before verifying
+		candidates := state.Request.FilterCandidates(state.Candidates)
+		filtered := len(state.Candidates) - len(candidates)
+		if filtered > 0 {
+			log.Printf("[Eino] Filtered out %d candidates outside the requested scope", filtered)
+		}
+
+		log.Printf("[Eino] Verifying %d candidates", len(candidates))
 
 		verifyReq := &models.VerificationRequest{
-			Candidates: state.Candidates,
+			Candidates: candidates,
 		}
 
 		resp, err := oa.callVerificationAgent(ctx, verifyReq)
@@ -158,11 +176,22 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 			}
 		}
 
+		// Rank statistics so the most reputable sources come first, then merge
+		// statistics of the same fact into the best-sourced one, so the quality
+		// check counts distinct facts
+		oa.reputation.WithOverrides(state.Request.AllowDomains, state.Request.DenyDomains).RankStatistics(verifiedStats)
+		distinct := models.ClusterStatistics(verifiedStats)
+		if merged := len(verifiedStats) - len(distinct); merged > 0 {
+			log.Printf("[Eino] Merged %d statistics into other sources of the same fact", merged)
+		}
+
 		return &VerificationState{
 			Request:       state.Request,
 			AllCandidates: state.Candidates,
-			Verified:      verifiedStats,
+			Verified:      distinct,
 			Failed:        resp.Failed,
+			Merged:        len(verifiedStats) - len(distinct),
+			Filtered:      filtered + state.Irrelevant,
 		}, nil
 	})
 	if err := g.AddLambdaNode(nodeVerification, verificationLambda); err != nil {
@@ -231,6 +260,8 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 			TotalCandidates: len(state.AllCandidates),
 			VerifiedCount:   verifiedCount,
 			FailedCount:     state.Failed,
+			FilteredCount:   state.Filtered,
+			MergedCount:     state.Merged,
 			Timestamp:       time.Now(),
 			Partial:         isPartial,
 			TargetCount:     targetCount,
@@ -281,7 +312,7 @@ func (oa *EinoOrchestrationAgent) Orchestrate(ctx context.Context, req *models.O
 
 func (oa *EinoOrchestrationAgent) callResearchAgent(ctx context.Context, req *models.ResearchRequest) (*models.ResearchResponse, error) {
 	var resp models.ResearchResponse
-	url := fmt.Sprintf("%s/research", oa.cfg.ResearchAgentURL)
+	url := fmt.Sprintf("%s/v2/research", oa.cfg.ResearchAgentURL)
 	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
 		return nil, err
 	}
@@ -341,6 +372,7 @@ type SynthesisState struct {
 	Request       *models.OrchestrationRequest
 	SearchResults []models.SearchResult
 	Candidates    []models.CandidateStatistic
+	Irrelevant    int // Candidates synthesis dropped below the minimum relevance
 }
 
 type VerificationState struct {
@@ -348,6 +380,8 @@ type VerificationState struct {
 	AllCandidates []models.CandidateStatistic
 	Verified      []models.Statistic
 	Failed        int
+	Filtered      int
+	Merged        int // Verified statistics merged into another of the same fact
 }
 
 type QualityDecision struct {
@@ -0,0 +1,307 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// objectHeader matches the start of an indirect object ("12 0 obj")
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// document holds the indirect objects of a PDF file. Objects are located by scanning
+// for object headers rather than trusting the cross-reference table, which keeps
+// extraction working for files with damaged or missing xref sections.
+type document struct {
+	objects map[int]object
+}
+
+// parseDocument scans a PDF file and loads its indirect objects, including
+// objects packed into object streams
+func parseDocument(data []byte) (*document, error) {
+	if !IsPDF(data) {
+		return nil, errors.New("not a PDF file")
+	}
+
+	doc := &document{objects: map[int]object{}}
+	for _, m := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		num := atoi(data[m[2]:m[3]])
+		lx := &lexer{data: data, pos: m[1]}
+		obj := lx.next(true)
+		if d, ok := obj.(dict); ok {
+			if s, ok := readStream(data, lx.pos, d); ok {
+				obj = s
+			}
+		}
+		// Later definitions win, matching incremental update semantics
+		doc.objects[num] = obj
+	}
+
+	for _, num := range doc.objectNumbers() {
+		if s, ok := doc.objects[num].(*stream); ok && s.dict["Type"] == name("ObjStm") {
+			doc.loadObjectStream(s)
+		}
+	}
+
+	if len(doc.objects) == 0 {
+		return nil, errors.New("no objects found in PDF")
+	}
+	return doc, nil
+}
+
+// objectNumbers returns the object numbers in ascending order
+func (d *document) objectNumbers() []int {
+	nums := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// readStream reads stream data following a stream dictionary that ends at pos
+func readStream(data []byte, pos int, d dict) (*stream, bool) {
+	lx := &lexer{data: data, pos: pos}
+	lx.skipSpace()
+	if lx.pos >= len(data) || !bytes.HasPrefix(data[lx.pos:], []byte("stream")) {
+		return nil, false
+	}
+	start := lx.pos + len("stream")
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	// Trust a direct /Length when it lands on the endstream keyword
+	if length, ok := d["Length"].(int); ok && length >= 0 && length <= len(data)-start {
+		rest := bytes.TrimLeft(data[start+length:], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return &stream{dict: d, raw: data[start : start+length]}, true
+		}
+	}
+
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return &stream{dict: d, raw: data[start:]}, true
+	}
+	raw := data[start : start+end]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return &stream{dict: d, raw: raw}, true
+}
+
+// loadObjectStream adds the objects packed into an object stream (PDF 1.5+),
+// without replacing objects defined directly in the file
+func (d *document) loadObjectStream(s *stream) {
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(s.dict["N"]).(int)
+	first, _ := d.resolve(s.dict["First"]).(int)
+	if first <= 0 || first > len(data) {
+		return
+	}
+
+	header := &lexer{data: data[:first]}
+	for i := 0; i < n; i++ {
+		num, ok1 := header.next(false).(int)
+		offset, ok2 := header.next(false).(int)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, exists := d.objects[num]; exists {
+			continue
+		}
+		if offset < 0 || first+offset >= len(data) {
+			continue
+		}
+		lx := &lexer{data: data, pos: first + offset}
+		d.objects[num] = lx.next(true)
+	}
+}
+
+// resolve follows indirect references until it reaches a direct object
+func (d *document) resolve(obj object) object {
+	for i := 0; i < 32; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[r.num]
+	}
+	return nil
+}
+
+// dictOf resolves obj and returns its dictionary, including a stream's dictionary
+func (d *document) dictOf(obj object) dict {
+	switch v := d.resolve(obj).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.dict
+	}
+	return nil
+}
+
+// decode applies a stream's filters and returns the decoded data
+func (d *document) decode(s *stream) ([]byte, error) {
+	data := s.raw
+	filters := d.resolve(s.dict["Filter"])
+	params := d.resolve(s.dict["DecodeParms"])
+
+	var filterList, paramList array
+	switch f := filters.(type) {
+	case name:
+		filterList = array{f}
+		paramList = array{params}
+	case array:
+		filterList = f
+		if p, ok := params.(array); ok {
+			paramList = p
+		}
+	}
+
+	for i, f := range filterList {
+		var p dict
+		if i < len(paramList) {
+			p = d.dictOf(paramList[i])
+		}
+		var err error
+		switch d.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = inflate(data)
+			if err == nil {
+				data, err = d.unpredict(data, p)
+			}
+		case name("ASCIIHexDecode"), name("AHx"):
+			if end := bytes.IndexByte(data, '>'); end >= 0 {
+				data = data[:end]
+			}
+			data = decodeHex(bytes.Join(bytes.Fields(data), nil))
+		case name("ASCII85Decode"), name("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, errors.New("unsupported stream filter")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate decompresses zlib data, keeping whatever was recovered from truncated streams
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if end := bytes.Index(data, []byte("~>")); end >= 0 {
+		data = data[:end]
+	}
+	out := make([]byte, 4*len(data)/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
+
+// unpredict reverses PNG predictors (Predictor >= 10) applied before Flate compression
+func (d *document) unpredict(data []byte, params dict) ([]byte, error) {
+	predictor, _ := d.resolve(params["Predictor"]).(int)
+	if predictor < 10 {
+		return data, nil
+	}
+	columns, _ := d.resolve(params["Columns"]).(int)
+	if columns <= 0 {
+		columns = 1
+	}
+	colors, _ := d.resolve(params["Colors"]).(int)
+	if colors <= 0 {
+		colors = 1
+	}
+	bpc, _ := d.resolve(params["BitsPerComponent"]).(int)
+	if bpc <= 0 {
+		bpc = 8
+	}
+	bpp := (colors*bpc + 7) / 8
+	rowLen := (columns*colors*bpc + 7) / 8
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for i := 0; i+rowLen < len(data)+1 && i < len(data); i += rowLen + 1 {
+		filter := data[i]
+		end := i + 1 + rowLen
+		if end > len(data) {
+			end = len(data)
+		}
+		row := make([]byte, rowLen)
+		copy(row, data[i+1:end])
+		for j := range row {
+			var left, up, upLeft byte
+			if j >= bpp {
+				left = row[j-bpp]
+				upLeft = prev[j-bpp]
+			}
+			up = prev[j]
+			switch filter {
+			case 1:
+				row[j] += left
+			case 2:
+				row[j] += up
+			case 3:
+				row[j] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[j] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func atoi(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
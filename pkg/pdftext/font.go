@@ -0,0 +1,255 @@
+package pdftext
+
+import (
+	"strconv"
+	"strings"
+)
+
+// font decodes character codes in shown strings to Unicode text
+type font struct {
+	toUnicode map[string]string // Code bytes to text, from the ToUnicode CMap
+	codeLens  []int             // Code lengths in bytes, longest first
+	composite bool              // Type0 font with multi-byte codes
+	encoding  map[byte]rune     // Simple font encoding differences
+}
+
+// loadFont builds a decoder from a font dictionary
+func (d *document) loadFont(fd dict) *font {
+	f := &font{composite: fd["Subtype"] == name("Type0")}
+
+	if s, ok := d.resolve(fd["ToUnicode"]).(*stream); ok {
+		if data, err := d.decode(s); err == nil {
+			f.parseCMap(data)
+		}
+	}
+	if len(f.codeLens) == 0 {
+		if f.composite {
+			f.codeLens = []int{2}
+		} else {
+			f.codeLens = []int{1}
+		}
+	}
+
+	if enc := d.dictOf(fd["Encoding"]); enc != nil {
+		if diffs, ok := d.resolve(enc["Differences"]).(array); ok {
+			f.encoding = map[byte]rune{}
+			code := 0
+			for _, item := range diffs {
+				switch v := d.resolve(item).(type) {
+				case int:
+					code = v
+				case name:
+					if r, ok := glyphRune(string(v)); ok && code >= 0 && code < 256 {
+						f.encoding[byte(code)] = r
+					}
+					code++
+				}
+			}
+		}
+	}
+	return f
+}
+
+// parseCMap reads codespace ranges and bfchar/bfrange mappings from a ToUnicode CMap
+func (f *font) parseCMap(data []byte) {
+	f.toUnicode = map[string]string{}
+	lengths := map[int]bool{}
+	lx := &lexer{data: data}
+
+	var operands []object
+	for {
+		obj := lx.next(false)
+		if obj == nil && lx.pos >= len(data) {
+			break
+		}
+		op, isOp := obj.(keyword)
+		if !isOp {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].([]byte); ok && len(lo) > 0 {
+					lengths[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					f.toUnicode[string(src)] = utf16BE(dst)
+					lengths[len(src)] = true
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				lengths[len(lo)] = true
+				f.mapRange(lo, hi, operands[i+2])
+			}
+		}
+		if strings.HasPrefix(string(op), "end") || strings.HasPrefix(string(op), "begin") {
+			operands = operands[:0]
+		}
+	}
+
+	for n := 4; n >= 1; n-- {
+		if lengths[n] {
+			f.codeLens = append(f.codeLens, n)
+		}
+	}
+}
+
+// mapRange adds a bfrange mapping, either incrementing a base destination or
+// taking destinations from an array
+func (f *font) mapRange(lo, hi []byte, dst object) {
+	start, end := bytesToInt(lo), bytesToInt(hi)
+	if end < start || end-start > 0xFFFF {
+		return
+	}
+	for code := start; code <= end; code++ {
+		src := string(intToBytes(code, len(lo)))
+		switch v := dst.(type) {
+		case []byte:
+			if len(v) == 0 {
+				continue
+			}
+			base := append([]byte(nil), v...)
+			// Increment the last byte of the destination for each code in the range
+			offset := code - start
+			last := int(base[len(base)-1]) + offset
+			base[len(base)-1] = byte(last & 0xFF)
+			if last > 0xFF && len(base) >= 2 {
+				base[len(base)-2] += byte(last >> 8)
+			}
+			f.toUnicode[src] = utf16BE(base)
+		case array:
+			if idx := code - start; idx < len(v) {
+				if b, ok := v[idx].([]byte); ok {
+					f.toUnicode[src] = utf16BE(b)
+				}
+			}
+		}
+	}
+}
+
+// decode converts the bytes of a shown string to text
+func (f *font) decode(s []byte) string {
+	if f == nil {
+		return decodeSimple(s, nil)
+	}
+	if f.toUnicode == nil {
+		if f.composite {
+			// Without a ToUnicode map, CIDs cannot be mapped to characters
+			return ""
+		}
+		return decodeSimple(s, f.encoding)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		matched := false
+		for _, n := range f.codeLens {
+			if i+n > len(s) {
+				continue
+			}
+			if text, ok := f.toUnicode[string(s[i:i+n])]; ok {
+				b.WriteString(text)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if !f.composite {
+			b.WriteString(decodeSimple(s[i:i+1], f.encoding))
+		}
+		i += f.codeLens[len(f.codeLens)-1]
+	}
+	return b.String()
+}
+
+// decodeSimple decodes single-byte codes using encoding differences, falling back
+// to WinAnsiEncoding, which agrees with Latin-1 outside 0x80-0x9F
+func decodeSimple(s []byte, encoding map[byte]rune) string {
+	var b strings.Builder
+	for _, c := range s {
+		if r, ok := encoding[c]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		if r, ok := winAnsi[c]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		if c >= 0x20 || c == '\t' || c == '\n' {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// winAnsi maps the WinAnsiEncoding codes that differ from Latin-1
+var winAnsi = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// glyphNames maps common Adobe glyph names used in encoding differences
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5', "six": '6',
+	"seven": '7', "eight": '8', "nine": '9', "colon": ':', "semicolon": ';', "less": '<',
+	"equal": '=', "greater": '>', "question": '?', "at": '@', "bracketleft": '[',
+	"backslash": '\\', "bracketright": ']', "underscore": '_', "braceleft": '{', "bar": '|',
+	"braceright": '}', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "endash": '–', "emdash": '—', "bullet": '•', "ellipsis": '…',
+	"degree": '°', "plusminus": '±', "multiply": '×', "divide": '÷', "minus": '−',
+	"Euro": '€', "sterling": '£', "yen": '¥', "section": '§', "copyright": '©',
+	"registered": '®', "trademark": '™', "fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ',
+}
+
+// glyphRune resolves an Adobe glyph name to a rune
+func glyphRune(glyph string) (rune, bool) {
+	if r, ok := glyphNames[glyph]; ok {
+		return r, true
+	}
+	if len(glyph) == 1 {
+		return rune(glyph[0]), true
+	}
+	if hex, ok := strings.CutPrefix(glyph, "uni"); ok && len(hex) == 4 {
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	return 0, false
+}
+
+func bytesToInt(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+func intToBytes(n, size int) []byte {
+	b := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
@@ -0,0 +1,304 @@
+package pdftext
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// PDF object model (ISO 32000-1 section 7.3)
+type (
+	object  interface{}
+	name    string
+	keyword string
+	dict    map[name]object
+	array   []object
+	ref     struct{ num, gen int }
+	stream  struct {
+		dict dict
+		raw  []byte
+	}
+)
+
+// lexer reads PDF objects and content stream operators from a byte slice
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace and comments
+func (lx *lexer) skipSpace() {
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if isWhitespace(c) {
+			lx.pos++
+			continue
+		}
+		if c == '%' {
+			for lx.pos < len(lx.data) && lx.data[lx.pos] != '\n' && lx.data[lx.pos] != '\r' {
+				lx.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// next reads the next object or keyword. It returns nil at end of input.
+// When refs is true, "num gen R" sequences are read as indirect references.
+func (lx *lexer) next(refs bool) object {
+	lx.skipSpace()
+	if lx.pos >= len(lx.data) {
+		return nil
+	}
+
+	c := lx.data[lx.pos]
+	switch {
+	case c == '/':
+		return lx.readName()
+	case c == '(':
+		return lx.readLiteralString()
+	case c == '<':
+		if lx.pos+1 < len(lx.data) && lx.data[lx.pos+1] == '<' {
+			lx.pos += 2
+			return lx.readDict(refs)
+		}
+		return lx.readHexString()
+	case c == '[':
+		lx.pos++
+		return lx.readArray(refs)
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		lx.pos++
+		if c == '>' && lx.pos < len(lx.data) && lx.data[lx.pos] == '>' {
+			lx.pos++
+			return keyword(">>")
+		}
+		return keyword(string(c))
+	}
+
+	start := lx.pos
+	for lx.pos < len(lx.data) && !isWhitespace(lx.data[lx.pos]) && !isDelimiter(lx.data[lx.pos]) {
+		lx.pos++
+	}
+	token := string(lx.data[start:lx.pos])
+	if token == "" {
+		// Stray delimiter; skip it so callers always make progress
+		lx.pos++
+		return keyword(string(c))
+	}
+
+	if num, ok := parseNumber(token); ok {
+		if refs {
+			if i, isInt := num.(int); isInt {
+				if r, ok := lx.tryRef(i); ok {
+					return r
+				}
+			}
+		}
+		return num
+	}
+
+	switch token {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return keyword(token)
+}
+
+// tryRef attempts to read "gen R" after an integer, restoring the position on failure
+func (lx *lexer) tryRef(num int) (ref, bool) {
+	save := lx.pos
+	lx.skipSpace()
+	start := lx.pos
+	for lx.pos < len(lx.data) && lx.data[lx.pos] >= '0' && lx.data[lx.pos] <= '9' {
+		lx.pos++
+	}
+	if lx.pos > start {
+		gen, err := strconv.Atoi(string(lx.data[start:lx.pos]))
+		lx.skipSpace()
+		if err == nil && lx.pos < len(lx.data) && lx.data[lx.pos] == 'R' &&
+			(lx.pos+1 == len(lx.data) || isWhitespace(lx.data[lx.pos+1]) || isDelimiter(lx.data[lx.pos+1])) {
+			lx.pos++
+			return ref{num: num, gen: gen}, true
+		}
+	}
+	lx.pos = save
+	return ref{}, false
+}
+
+func parseNumber(token string) (object, bool) {
+	c := token[0]
+	if (c < '0' || c > '9') && c != '+' && c != '-' && c != '.' {
+		return nil, false
+	}
+	if i, err := strconv.Atoi(token); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
+
+func (lx *lexer) readName() object {
+	lx.pos++ // skip '/'
+	var b bytes.Buffer
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if isWhitespace(c) || isDelimiter(c) {
+			break
+		}
+		if c == '#' && lx.pos+2 < len(lx.data) {
+			if v, err := strconv.ParseUint(string(lx.data[lx.pos+1:lx.pos+3]), 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				lx.pos += 3
+				continue
+			}
+		}
+		b.WriteByte(c)
+		lx.pos++
+	}
+	return name(b.String())
+}
+
+func (lx *lexer) readLiteralString() object {
+	lx.pos++ // skip '('
+	var b bytes.Buffer
+	depth := 1
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		lx.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return b.Bytes()
+			}
+		case '\\':
+			if lx.pos >= len(lx.data) {
+				return b.Bytes()
+			}
+			e := lx.data[lx.pos]
+			lx.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case '\r':
+				// Line continuation
+				if lx.pos < len(lx.data) && lx.data[lx.pos] == '\n' {
+					lx.pos++
+				}
+			case '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && lx.pos < len(lx.data) && lx.data[lx.pos] >= '0' && lx.data[lx.pos] <= '7'; i++ {
+						v = v*8 + int(lx.data[lx.pos]-'0')
+						lx.pos++
+					}
+					b.WriteByte(byte(v))
+				} else {
+					b.WriteByte(e)
+				}
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.Bytes()
+}
+
+func (lx *lexer) readHexString() object {
+	lx.pos++ // skip '<'
+	var digits []byte
+	for lx.pos < len(lx.data) && lx.data[lx.pos] != '>' {
+		c := lx.data[lx.pos]
+		if !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+		lx.pos++
+	}
+	if lx.pos < len(lx.data) {
+		lx.pos++ // skip '>'
+	}
+	return decodeHex(digits)
+}
+
+// decodeHex decodes hex digits, padding an odd trailing digit with 0
+func decodeHex(digits []byte) []byte {
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		out = append(out, byte(v))
+	}
+	return out
+}
+
+func (lx *lexer) readArray(refs bool) object {
+	arr := array{}
+	for {
+		lx.skipSpace()
+		if lx.pos >= len(lx.data) {
+			return arr
+		}
+		if lx.data[lx.pos] == ']' {
+			lx.pos++
+			return arr
+		}
+		arr = append(arr, lx.next(refs))
+	}
+}
+
+func (lx *lexer) readDict(refs bool) object {
+	d := dict{}
+	for {
+		lx.skipSpace()
+		if lx.pos >= len(lx.data) {
+			return d
+		}
+		if lx.data[lx.pos] == '>' {
+			lx.pos++
+			if lx.pos < len(lx.data) && lx.data[lx.pos] == '>' {
+				lx.pos++
+			}
+			return d
+		}
+		key, ok := lx.next(refs).(name)
+		if !ok {
+			continue
+		}
+		d[key] = lx.next(refs)
+	}
+}
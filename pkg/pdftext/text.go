@@ -0,0 +1,378 @@
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// Page is the extracted text of a single PDF page
+type Page struct {
+	Number int    // 1-based page number
+	Text   string // Page text with one line per text line in the PDF
+}
+
+// pdfMagic is the header every PDF file starts with
+var pdfMagic = []byte("%PDF-")
+
+// IsPDF reports whether data looks like a PDF file. The header may be preceded by
+// a small amount of junk, which readers are required to tolerate.
+func IsPDF(data []byte) bool {
+	limit := len(data)
+	if limit > 1024 {
+		limit = 1024
+	}
+	return bytes.Contains(data[:limit], pdfMagic)
+}
+
+// Extract returns the text of each page of a PDF in page order. Encrypted PDFs are
+// rejected with an error, and pages drawn only with images yield no text.
+func Extract(data []byte) (pages []Page, err error) {
+	// Malformed input must not take down the caller; report it as a parse error
+	defer func() {
+		if r := recover(); r != nil {
+			pages, err = nil, fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc.encrypted(data) {
+		return nil, errors.New("encrypted PDFs are not supported")
+	}
+
+	docPages := doc.pages()
+	if len(docPages) == 0 {
+		return nil, errors.New("no pages found in PDF")
+	}
+
+	result := make([]Page, 0, len(docPages))
+	for i, p := range docPages {
+		ex := &extractor{doc: doc, fonts: map[string]*font{}}
+		ex.runContent(doc.pageContent(p.dict), p.resources, 0)
+		result = append(result, Page{Number: i + 1, Text: ex.text()})
+	}
+	return result, nil
+}
+
+// Text returns the text of a PDF with pages separated by form feeds
+func Text(data []byte) (string, error) {
+	pages, err := Extract(data)
+	if err != nil {
+		return "", err
+	}
+	texts := make([]string, len(pages))
+	for i, p := range pages {
+		texts[i] = p.Text
+	}
+	return strings.Join(texts, "\f"), nil
+}
+
+// trailerStart matches the keyword and opening of a trailer dictionary
+var trailerStart = regexp.MustCompile(`trailer\s*<<`)
+
+// encrypted reports whether a trailer dictionary or cross-reference stream has
+// an /Encrypt entry. Only those dictionaries are checked, so "/Encrypt" written
+// in page content or metadata doesn't count.
+func (d *document) encrypted(data []byte) bool {
+	for _, m := range trailerStart.FindAllIndex(data, -1) {
+		lx := &lexer{data: data, pos: m[0] + len("trailer")}
+		if trailer, ok := lx.next(true).(dict); ok {
+			if _, ok := trailer["Encrypt"]; ok {
+				return true
+			}
+		}
+	}
+	for _, obj := range d.objects {
+		if s, ok := obj.(*stream); ok && s.dict["Type"] == name("XRef") {
+			if _, ok := s.dict["Encrypt"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pageNode is a leaf of the page tree with its inherited resources
+type pageNode struct {
+	dict      dict
+	resources dict
+}
+
+// pages walks the page tree from the document catalog, falling back to every
+// /Type /Page object in object order when the tree cannot be found
+func (d *document) pages() []pageNode {
+	var catalog dict
+	for _, num := range d.objectNumbers() {
+		if dd := d.dictOf(d.objects[num]); dd != nil && dd["Type"] == name("Catalog") {
+			catalog = dd
+		}
+	}
+
+	var pages []pageNode
+	if catalog != nil {
+		seen := map[int]bool{}
+		var walk func(node object, resources dict, depth int)
+		walk = func(node object, resources dict, depth int) {
+			if r, ok := node.(ref); ok {
+				if seen[r.num] {
+					return
+				}
+				seen[r.num] = true
+			}
+			nd := d.dictOf(node)
+			if nd == nil || depth > 64 {
+				return
+			}
+			if res := d.dictOf(nd["Resources"]); res != nil {
+				resources = res
+			}
+			if kids, ok := d.resolve(nd["Kids"]).(array); ok {
+				for _, kid := range kids {
+					walk(kid, resources, depth+1)
+				}
+				return
+			}
+			pages = append(pages, pageNode{dict: nd, resources: resources})
+		}
+		walk(catalog["Pages"], nil, 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	for _, num := range d.objectNumbers() {
+		if dd := d.dictOf(d.objects[num]); dd != nil && dd["Type"] == name("Page") {
+			pages = append(pages, pageNode{dict: dd, resources: d.dictOf(dd["Resources"])})
+		}
+	}
+	return pages
+}
+
+// pageContent returns the decoded, concatenated content streams of a page
+func (d *document) pageContent(page dict) []byte {
+	var parts []object
+	switch c := d.resolve(page["Contents"]).(type) {
+	case *stream:
+		parts = []object{c}
+	case array:
+		parts = c
+	}
+
+	var buf bytes.Buffer
+	for _, part := range parts {
+		s, ok := d.resolve(part).(*stream)
+		if !ok {
+			continue
+		}
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// extractor interprets content stream text operators and accumulates page text
+type extractor struct {
+	doc   *document
+	fonts map[string]*font
+	out   strings.Builder
+	lastY float64
+}
+
+// runContent interprets a content stream with the given resources. Form XObjects
+// are followed recursively up to a fixed depth.
+func (ex *extractor) runContent(content []byte, resources dict, depth int) {
+	if depth > 8 {
+		return
+	}
+	var current *font
+	var operands []object
+	lx := &lexer{data: content}
+
+	for {
+		obj := lx.next(false)
+		if obj == nil && lx.pos >= len(content) {
+			return
+		}
+		op, isOp := obj.(keyword)
+		if !isOp {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "BI":
+			// Skip inline image data, which is raw binary
+			if end := bytes.Index(content[lx.pos:], []byte("EI")); end >= 0 {
+				lx.pos += end + 2
+			} else {
+				lx.pos = len(content)
+			}
+		case "BT":
+			ex.lastY = 0
+		case "ET":
+			ex.space()
+		case "Tf":
+			if len(operands) >= 2 {
+				if fontName, ok := operands[len(operands)-2].(name); ok {
+					current = ex.font(resources, fontName)
+				}
+			}
+		case "Tj":
+			if len(operands) >= 1 {
+				ex.show(current, operands[len(operands)-1])
+			}
+		case "'":
+			ex.newline()
+			if len(operands) >= 1 {
+				ex.show(current, operands[len(operands)-1])
+			}
+		case "\"":
+			ex.newline()
+			if len(operands) >= 3 {
+				ex.show(current, operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if arr, ok := operands[len(operands)-1].(array); ok {
+					for _, item := range arr {
+						if adj, ok := toFloat(item); ok {
+							// Large negative adjustments (in thousandths of an em) are word gaps
+							if adj < -200 {
+								ex.space()
+							}
+							continue
+						}
+						ex.show(current, item)
+					}
+				}
+			}
+		case "Td", "TD":
+			// Moves to a new line start a line; moves along the line separate text runs
+			if len(operands) >= 2 {
+				if ty, ok := toFloat(operands[len(operands)-1]); ok && ty != 0 {
+					ex.newline()
+				} else {
+					ex.space()
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				y, _ := toFloat(operands[len(operands)-1])
+				if y != ex.lastY {
+					ex.newline()
+				} else {
+					ex.space()
+				}
+				ex.lastY = y
+			}
+		case "T*":
+			ex.newline()
+		case "Do":
+			if len(operands) >= 1 {
+				if xname, ok := operands[len(operands)-1].(name); ok {
+					ex.runXObject(resources, xname, depth)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// runXObject interprets a form XObject referenced from a content stream
+func (ex *extractor) runXObject(resources dict, xname name, depth int) {
+	xobjects := ex.doc.dictOf(resources["XObject"])
+	s, ok := ex.doc.resolve(xobjects[xname]).(*stream)
+	if !ok || s.dict["Subtype"] != name("Form") {
+		return
+	}
+	data, err := ex.doc.decode(s)
+	if err != nil {
+		return
+	}
+	formResources := ex.doc.dictOf(s.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+	ex.runContent(data, formResources, depth+1)
+}
+
+// font returns the decoder for a font resource, caching by resource name
+func (ex *extractor) font(resources dict, fontName name) *font {
+	fonts := ex.doc.dictOf(resources["Font"])
+	fd := ex.doc.dictOf(fonts[fontName])
+	if fd == nil {
+		return nil
+	}
+	key := string(fontName)
+	if f, ok := ex.fonts[key]; ok {
+		return f
+	}
+	f := ex.doc.loadFont(fd)
+	ex.fonts[key] = f
+	return f
+}
+
+// show decodes a string operand with the current font and appends it
+func (ex *extractor) show(f *font, operand object) {
+	s, ok := operand.([]byte)
+	if !ok {
+		return
+	}
+	ex.out.WriteString(f.decode(s))
+}
+
+func (ex *extractor) space() {
+	text := ex.out.String()
+	if text != "" && !strings.HasSuffix(text, " ") && !strings.HasSuffix(text, "\n") {
+		ex.out.WriteByte(' ')
+	}
+}
+
+func (ex *extractor) newline() {
+	text := ex.out.String()
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		ex.out.WriteByte('\n')
+	}
+}
+
+// text returns the accumulated page text with whitespace tidied up
+func (ex *extractor) text() string {
+	lines := strings.Split(ex.out.String(), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func toFloat(obj object) (float64, bool) {
+	switch v := obj.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// utf16BE decodes big-endian UTF-16, as used by ToUnicode CMap destinations
+func utf16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
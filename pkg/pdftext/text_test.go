@@ -0,0 +1,92 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF returns a one-page PDF drawing content with Helvetica. A non-empty
+// filter compresses the content stream; trailer is added to the trailer dictionary.
+func buildPDF(t testing.TB, content, filter, trailer string) []byte {
+	t.Helper()
+	stream := []byte(content)
+	streamDict := fmt.Sprintf("/Length %d", len(stream))
+	if filter == "FlateDecode" {
+		var b bytes.Buffer
+		w := zlib.NewWriter(&b)
+		if _, err := w.Write(stream); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		stream = b.Bytes()
+		streamDict = fmt.Sprintf("/Length %d /Filter /FlateDecode", len(stream))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	b.WriteString("2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj\n")
+	b.WriteString("3 0 obj << /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >> endobj\n")
+	fmt.Fprintf(&b, "4 0 obj << %s >> stream\n", streamDict)
+	b.Write(stream)
+	b.WriteString("\nendstream endobj\n")
+	b.WriteString("5 0 obj << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> endobj\n")
+	fmt.Fprintf(&b, "trailer << /Root 1 0 R %s >>\n%%%%EOF\n", trailer)
+	return b.Bytes()
+}
+
+const testContent = "BT /F1 12 Tf 72 712 Td (EV sales rose 35%) Tj 0 -14 Td (to 14 million in 2023) Tj ET"
+
+func TestExtract(t *testing.T) {
+	plain := buildPDF(t, testContent, "", "")
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string // Text of the first page
+		wantErr string
+	}{
+		{"plain", plain, "EV sales rose 35%\nto 14 million in 2023", ""},
+		{"compressed stream", buildPDF(t, testContent, "FlateDecode", ""), "EV sales rose 35%\nto 14 million in 2023", ""},
+		{"encrypted", buildPDF(t, testContent, "", "/Encrypt 6 0 R"), "", "encrypted"},
+		{"encrypt name in content", buildPDF(t, "BT /F1 12 Tf (see /Encrypt) Tj ET", "", ""), "see /Encrypt", ""},
+		{"truncated after header", []byte("%PDF-00000 0 obj <<<"), "", "no pages"},
+		{"truncated in stream", plain[:bytes.Index(plain, []byte("0 -14 Td"))], "EV sales rose 35%", ""},
+		{"truncated after content", plain[:bytes.Index(plain, []byte("5 0 obj"))], "EV sales rose 35%\nto 14 million in 2023", ""},
+		{"not a PDF", []byte("<html><body>EV sales</body></html>"), "", "not a PDF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := Extract(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Extract() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if len(pages) != 1 {
+				t.Fatalf("Extract() returned %d pages, want 1", len(pages))
+			}
+			if got := strings.TrimSpace(pages[0].Text); got != tt.want {
+				t.Errorf("Extract() text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzExtract(f *testing.F) {
+	f.Add([]byte("%PDF-00000 0 obj <<<"))
+	f.Add(buildPDF(f, testContent, "", ""))
+	f.Add(buildPDF(f, testContent, "FlateDecode", ""))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Malformed input may fail but must never panic
+		_, _ = Extract(data)
+	})
+}
@@ -0,0 +1,188 @@
+// Package prompts is the registry of LLM prompts and agent instructions. Prompts
+// are Go text/templates embedded in the binary under templates/<id>/<variant>.tmpl
+// and can be overridden or extended, file by file, from a directory with the
+// same layout (PROMPTS_DIR), so they can be tuned without a rebuild. Each
+// template declares its version in a leading comment:
+//
+//	{{/* version: 2 */ -}}
+//
+// The ID, variant and version of the prompt that produced a statistic are
+// recorded on it, and requests can pick a variant for A/B comparisons.
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/grokify/stats-agent-team/pkg/config"
+)
+
+// Prompt IDs
+const (
+	SynthesisExtract            = "synthesis.extract"             // Statistics in a page's text
+	SynthesisTables             = "synthesis.tables"              // Cell selection from a page's tables
+	SynthesisInstruction        = "synthesis.instruction"         // Synthesis agent instruction
+	VerificationInstruction     = "verification.instruction"      // Verification agent instruction
+	DirectSearch                = "direct.search"                 // Statistics from the model's own knowledge
+	OrchestrationInstruction    = "orchestration.instruction"     // ADK orchestration agent instruction
+	OrchestrationA2AInstruction = "orchestration.a2a_instruction" // Eino orchestration A2A agent instruction
+	RelevanceRate               = "relevance.rate"                // Relevance of statistics to a topic
+	SearchExpand                = "search.expand"                 // Alternative search queries for a topic
+	JSONRepair                  = "llm.repair"                    // Follow-up to a response that didn't match the JSON schema
+)
+
+// DefaultVariant is the variant used when none is requested
+const DefaultVariant = "default"
+
+//go:embed templates
+var embedded embed.FS
+
+// versionComment matches the version declaration at the start of a template
+var versionComment = regexp.MustCompile(`^\{\{-?\s*/\*\s*version:\s*([^\s*]+)\s*\*/\s*-?\}\}`)
+
+// Prompt is a parsed prompt template
+type Prompt struct {
+	ID      string
+	Variant string
+	Version string
+	Source  string // "embedded" or the override file path
+	tmpl    *template.Template
+}
+
+// Render executes the prompt template with data. Leading and trailing
+// whitespace is trimmed, so template files can end with a newline.
+func (p *Prompt) Render(data any) (string, error) {
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s/%s: %w", p.ID, p.Variant, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Registry holds the prompts by ID and variant
+type Registry struct {
+	prompts map[string]map[string]*Prompt
+}
+
+// Default returns the registry of embedded prompts
+func Default() *Registry {
+	r := &Registry{prompts: map[string]map[string]*Prompt{}}
+	sub, err := fs.Sub(embedded, "templates")
+	if err == nil {
+		err = r.add(sub, "embedded")
+	}
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded prompts: %v", err))
+	}
+	return r
+}
+
+// Load returns the embedded prompts, overridden and extended by the templates
+// in dir (dir/<id>/<variant>.tmpl)
+func Load(dir string) (*Registry, error) {
+	r := Default()
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+	if err := r.add(os.DirFS(dir), dir); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadFromConfig loads the prompts with the overrides in PROMPTS_DIR, if set
+func LoadFromConfig(cfg *config.Config) (*Registry, error) {
+	if cfg.PromptsDir == "" {
+		return Default(), nil
+	}
+	return Load(cfg.PromptsDir)
+}
+
+// add parses the <id>/<variant>.tmpl files of fsys into the registry
+func (r *Registry) add(fsys fs.FS, source string) error {
+	files, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt %s: %w", file, err)
+		}
+		id, variant := path.Dir(file), strings.TrimSuffix(path.Base(file), ".tmpl")
+		m := versionComment.FindSubmatch(data)
+		if m == nil {
+			return fmt.Errorf("prompt %s: missing {{/* version: N */}} comment", file)
+		}
+		tmpl, err := template.New(id + "/" + variant).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse prompt %s: %w", file, err)
+		}
+		from := source
+		if source != "embedded" {
+			from = path.Join(source, file)
+		}
+		if r.prompts[id] == nil {
+			r.prompts[id] = map[string]*Prompt{}
+		}
+		r.prompts[id][variant] = &Prompt{ID: id, Variant: variant, Version: string(m[1]), Source: from, tmpl: tmpl}
+	}
+	return nil
+}
+
+// Get returns a prompt in the given variant, or the default variant if variant
+// is empty. A variant the prompt doesn't have is an error; see Preferred for
+// variants that apply only where they exist.
+func (r *Registry) Get(id, variant string) (*Prompt, error) {
+	variants, ok := r.prompts[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt %q", id)
+	}
+	if variant == "" {
+		variant = DefaultVariant
+	}
+	if p, ok := variants[variant]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("prompt %q has no %q variant", id, variant)
+}
+
+// Variants returns the variants of a prompt in sorted order, nil for an unknown prompt
+func (r *Registry) Variants(id string) []string {
+	var variants []string
+	for v := range r.prompts[id] {
+		variants = append(variants, v)
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// Preferred returns variant if the prompt has it, else the default variant.
+// It resolves a variant set for all prompts (PROMPT_VARIANT), which most
+// prompts won't have.
+func (r *Registry) Preferred(id, variant string) string {
+	if _, ok := r.prompts[id][variant]; ok {
+		return variant
+	}
+	return DefaultVariant
+}
+
+// Render renders a prompt (see Get) and returns the text with the prompt used
+func (r *Registry) Render(id, variant string, data any) (string, *Prompt, error) {
+	p, err := r.Get(id, variant)
+	if err != nil {
+		return "", nil, err
+	}
+	text, err := p.Render(data)
+	if err != nil {
+		return "", nil, err
+	}
+	return text, p, nil
+}
@@ -0,0 +1,151 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTemplatesRender(t *testing.T) {
+	data := map[string]any{
+		SynthesisExtract: struct {
+			Topic, URL, Domain, Content string
+			Part, Parts                 int
+		}{"EV sales", "https://example.org", "example.org", "text", 1, 2},
+		SynthesisTables:         struct{ Topic, URL, Domain, Cells string }{"EV sales", "https://example.org", "example.org", "[t1c1] 2023 / Sales: 14"},
+		SynthesisInstruction:    struct{ ReputableSources string }{"gov"},
+		VerificationInstruction: nil,
+		DirectSearch: struct {
+			Topic    string
+			MinStats int
+		}{"EV sales", 5},
+		OrchestrationInstruction:    nil,
+		OrchestrationA2AInstruction: nil,
+		RelevanceRate:               struct{ Topic, Statistics string }{"EV sales", "[1] EV sales: 14 million"},
+		SearchExpand: struct {
+			N     int
+			Topic string
+		}{3, "EV sales"},
+		JSONRepair: struct{ Error, Schema string }{"missing id", `{"type":"array"}`},
+	}
+	r := Default()
+	for id, d := range data {
+		text, p, err := r.Render(id, "", d)
+		if err != nil {
+			t.Errorf("%s: %v", id, err)
+			continue
+		}
+		if p.Variant != DefaultVariant || p.Version == "" || p.Source != "embedded" {
+			t.Errorf("%s: variant %q version %q source %q", id, p.Variant, p.Version, p.Source)
+		}
+		if text == "" || strings.Contains(text, "version:") {
+			t.Errorf("%s rendered %q", id, text)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, SynthesisExtract, "concise", "{{/* version: 7 */ -}}\nExtract from {{.Topic}}.\n")
+
+	r, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	tests := []struct {
+		name, id, variant string
+		wantVariant       string
+		wantErr           string
+	}{
+		{"empty variant is the default", SynthesisExtract, "", DefaultVariant, ""},
+		{"default by name", SynthesisExtract, DefaultVariant, DefaultVariant, ""},
+		{"added variant", SynthesisExtract, "concise", "concise", ""},
+		{"unknown variant", SynthesisExtract, "verbose", "", `no "verbose" variant`},
+		{"variant of another prompt", SynthesisTables, "concise", "", `no "concise" variant`},
+		{"unknown prompt", "synthesis.summary", "", "", "unknown prompt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := r.Get(tt.id, tt.variant)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Get error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if p.Variant != tt.wantVariant {
+				t.Errorf("variant = %s, want %s", p.Variant, tt.wantVariant)
+			}
+		})
+	}
+
+	if got := strings.Join(r.Variants(SynthesisExtract), ","); got != "concise,default" {
+		t.Errorf("Variants(extract) = %s", got)
+	}
+	if got := r.Variants("synthesis.summary"); got != nil {
+		t.Errorf("Variants(unknown) = %v", got)
+	}
+	if got := r.Preferred(SynthesisExtract, "concise"); got != "concise" {
+		t.Errorf("Preferred(extract, concise) = %s", got)
+	}
+	if got := r.Preferred(SynthesisTables, "concise"); got != DefaultVariant {
+		t.Errorf("Preferred(tables, concise) = %s", got)
+	}
+	if got := r.Preferred(SynthesisTables, ""); got != DefaultVariant {
+		t.Errorf("Preferred(tables, \"\") = %s", got)
+	}
+}
+
+func TestLoadOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, SearchExpand, DefaultVariant, "{{/* version: 3 */ -}}\n{{.N}} queries on {{.Topic}}\n")
+
+	r, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	text, p, err := r.Render(SearchExpand, "", struct {
+		N     int
+		Topic string
+	}{2, "EV sales"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if text != "2 queries on EV sales" || p.Version != "3" || p.Source != filepath.Join(dir, SearchExpand, "default.tmpl") {
+		t.Errorf("got %q version %s from %s", text, p.Version, p.Source)
+	}
+	// Other prompts stay embedded
+	if p, _ := r.Get(RelevanceRate, ""); p.Source != "embedded" {
+		t.Errorf("relevance.rate source = %s", p.Source)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, SearchExpand, DefaultVariant, "no version comment")
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Load without version = %v", err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Load of a missing directory succeeded")
+	}
+
+	r := Default()
+	if _, _, err := r.Render(SearchExpand, "", struct{ N int }{3}); err == nil {
+		t.Error("Render with missing data succeeded")
+	}
+}
+
+func writeTemplate(t *testing.T, dir, id, variant, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, id), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, id, variant+".tmpl"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
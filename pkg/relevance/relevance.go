@@ -0,0 +1,192 @@
+// Package relevance scores how relevant extracted statistics are to the topic of
+// a request, so page trivia (visitor counts, copyright years, navigation) can be
+// filtered out before verification.
+package relevance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"google.golang.org/adk/model"
+
+	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/httpclient"
+	"github.com/grokify/stats-agent-team/pkg/llm"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+// Scorer modes (RELEVANCE_SCORER)
+const (
+	ModeLLM       = "llm"       // The extraction model judges each statistic
+	ModeEmbedding = "embedding" // Cosine similarity of embeddings from an Ollama-compatible endpoint
+	ModeOff       = "off"
+)
+
+// Scorer scores statistics against a topic, from 0 (unrelated) to 1 (directly
+// about it). Scores are returned in the order of the candidates. The scale
+// depends on the scorer: LLMScorer follows a rubric, EmbeddingScorer returns
+// similarities, so a minimum relevance suited to one doesn't suit the other.
+type Scorer interface {
+	Score(ctx context.Context, topic string, candidates []models.CandidateStatistic) ([]float64, error)
+}
+
+// NewFromConfig returns the scorer selected by RELEVANCE_SCORER, or nil if
+// scoring is off. The LLM scorer uses m and the relevance.rate prompt from
+// registry; the embedding scorer calls RELEVANCE_EMBEDDING_URL with client.
+func NewFromConfig(cfg *config.Config, m model.LLM, client *http.Client, registry *prompts.Registry) (Scorer, error) {
+	switch strings.ToLower(cfg.RelevanceScorer) {
+	case ModeOff, "":
+		return nil, nil
+	case ModeLLM:
+		return &LLMScorer{
+			Model:      m,
+			MaxRepairs: cfg.LLMMaxRepairs,
+			Prompts:    registry,
+			Variant:    registry.Preferred(prompts.RelevanceRate, cfg.PromptVariant),
+		}, nil
+	case ModeEmbedding:
+		return &EmbeddingScorer{URL: cfg.RelevanceEmbeddingURL, Model: cfg.RelevanceEmbeddingModel, Client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown relevance scorer %q (use llm, embedding or off)", cfg.RelevanceScorer)
+	}
+}
+
+// Filter returns the candidates scoring at least min, and how many were dropped.
+// Unscored candidates are kept.
+func Filter(candidates []models.CandidateStatistic, min float64) ([]models.CandidateStatistic, int) {
+	if min <= 0 {
+		return candidates, 0
+	}
+	kept := candidates[:0]
+	for _, c := range candidates {
+		if c.Relevance == nil || *c.Relevance >= min {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(candidates) - len(kept)
+}
+
+// describe renders a candidate for scoring: its name, value and excerpt
+func describe(c models.CandidateStatistic) string {
+	value := c.ValueText
+	if value == "" {
+		value = fmt.Sprintf("%g %s", c.Value, c.Unit)
+	}
+	return fmt.Sprintf("%s: %s (%q)", c.Name, strings.TrimSpace(value), c.Excerpt)
+}
+
+// llmBatchSize caps the statistics judged in one LLM call
+const llmBatchSize = 40
+
+// LLMScorer asks a model to rate each statistic's relevance to the topic
+type LLMScorer struct {
+	Model      model.LLM
+	MaxRepairs int               // Re-prompts after an invalid JSON response
+	Prompts    *prompts.Registry // Source of the relevance.rate prompt
+	Variant    string            // Prompt variant ("" for the default)
+}
+
+// Score rates the candidates in batches. Candidates the model leaves out score 0.
+func (s *LLMScorer) Score(ctx context.Context, topic string, candidates []models.CandidateStatistic) ([]float64, error) {
+	scores := make([]float64, len(candidates))
+	for start := 0; start < len(candidates); start += llmBatchSize {
+		batch := candidates[start:min(start+llmBatchSize, len(candidates))]
+
+		var listing strings.Builder
+		for i, c := range batch {
+			fmt.Fprintf(&listing, "[%d] %s\n", i+1, describe(c))
+		}
+
+		prompt, _, err := s.Prompts.Render(prompts.RelevanceRate, s.Variant, struct{ Topic, Statistics string }{topic, listing.String()})
+		if err != nil {
+			return nil, err
+		}
+
+		type Rating struct {
+			ID    int     `json:"id"`
+			Score float64 `json:"score"`
+		}
+
+		ratings, err := llm.GenerateJSON[[]Rating](ctx, s.Model, prompt, llm.JSONOptions{MaxRepairs: s.MaxRepairs, Prompts: s.Prompts})
+		if err != nil {
+			return nil, fmt.Errorf("failed to rate relevance: %w", err)
+		}
+		for _, r := range ratings {
+			if r.ID >= 1 && r.ID <= len(batch) {
+				scores[start+r.ID-1] = clamp(r.Score)
+			}
+		}
+	}
+	return scores, nil
+}
+
+// EmbeddingScorer scores statistics by the cosine similarity of their embedding
+// to the topic's, using an Ollama-compatible /api/embed endpoint. Similarities
+// are not rescaled to the LLM rubric: most embedding models put unrelated text
+// well above 0 and closely related text well below 1, and where depends on the
+// model, so minimum relevances must be calibrated for the embedding model.
+type EmbeddingScorer struct {
+	URL    string // Base URL, e.g., http://localhost:11434
+	Model  string // Embedding model, e.g., nomic-embed-text
+	Client *http.Client
+}
+
+// Score embeds the topic and candidates in one request. Similarities below 0
+// score 0.
+func (s *EmbeddingScorer) Score(ctx context.Context, topic string, candidates []models.CandidateStatistic) ([]float64, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	input := make([]string, 0, len(candidates)+1)
+	input = append(input, topic)
+	for _, c := range candidates {
+		input = append(input, describe(c))
+	}
+
+	req := struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}{Model: s.Model, Input: input}
+	var resp struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	url := strings.TrimSuffix(s.URL, "/") + "/api/embed"
+	if err := httpclient.PostJSON(ctx, s.Client, url, req, &resp); err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	if len(resp.Embeddings) != len(input) {
+		return nil, fmt.Errorf("embedding endpoint returned %d embeddings for %d inputs", len(resp.Embeddings), len(input))
+	}
+
+	scores := make([]float64, len(candidates))
+	for i := range candidates {
+		scores[i] = clamp(cosine(resp.Embeddings[0], resp.Embeddings[i+1]))
+	}
+	return scores, nil
+}
+
+// cosine returns the cosine similarity of two vectors, 0 if either is zero or
+// their lengths differ
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func clamp(score float64) float64 {
+	return math.Max(0, math.Min(1, score))
+}
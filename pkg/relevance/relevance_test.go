@@ -0,0 +1,169 @@
+package relevance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/models"
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+func score(s float64) *float64 { return &s }
+
+func TestFilter(t *testing.T) {
+	candidates := []models.CandidateStatistic{
+		{Name: "on topic", Relevance: score(0.9)},
+		{Name: "trivia", Relevance: score(0.1)},
+		{Name: "unscored"},
+		{Name: "borderline", Relevance: score(0.5)},
+	}
+	tests := []struct {
+		min         float64
+		wantKept    int
+		wantDropped int
+	}{
+		{0, 4, 0},
+		{0.5, 3, 1},
+		{0.95, 1, 3},
+	}
+	for _, tt := range tests {
+		in := append([]models.CandidateStatistic(nil), candidates...)
+		kept, dropped := Filter(in, tt.min)
+		if len(kept) != tt.wantKept || dropped != tt.wantDropped {
+			t.Errorf("Filter(min %.2f) kept %d dropped %d, want %d and %d", tt.min, len(kept), dropped, tt.wantKept, tt.wantDropped)
+		}
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"off", "", false},
+		{"llm", "*relevance.LLMScorer", false},
+		{"Embedding", "*relevance.EmbeddingScorer", false},
+		{"bm25", "", true},
+	}
+	for _, tt := range tests {
+		s, err := NewFromConfig(&config.Config{RelevanceScorer: tt.mode}, nil, nil, prompts.Default())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v", tt.mode, err)
+			continue
+		}
+		got := ""
+		if s != nil {
+			got = fmt.Sprintf("%T", s)
+		}
+		if got != tt.want {
+			t.Errorf("%q: scorer %s, want %s", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddingScorer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Input) != 4 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// Topic, then a parallel, an orthogonal and an opposite vector
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"embeddings": [][]float64{{1, 0}, {2, 0}, {0, 3}, {-1, 0}},
+		})
+	}))
+	defer srv.Close()
+
+	s := &EmbeddingScorer{URL: srv.URL + "/", Model: "nomic-embed-text", Client: srv.Client()}
+	scores, err := s.Score(context.Background(), "ev sales", []models.CandidateStatistic{
+		{Name: "a", Value: 14, Unit: "million"}, {Name: "b"}, {Name: "c"},
+	})
+	if err != nil {
+		t.Fatalf("Score: %v", err)
+	}
+	want := []float64{1, 0, 0}
+	for i := range want {
+		if math.Abs(scores[i]-want[i]) > 1e-9 {
+			t.Errorf("score %d = %g, want %g", i, scores[i], want[i])
+		}
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float64
+		want float64
+	}{
+		{[]float64{1, 0}, []float64{1, 0}, 1},
+		{[]float64{1, 1}, []float64{1, 0}, 1 / math.Sqrt2},
+		{[]float64{1, 0}, []float64{0, 1}, 0},
+		{[]float64{0, 0}, []float64{1, 0}, 0},
+		{[]float64{1}, []float64{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("cosine(%v, %v) = %g, want %g", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// fakeModel answers every request with a fixed response, recording the prompts
+type fakeModel struct {
+	response string
+	prompts  []string
+}
+
+func (m *fakeModel) Name() string { return "fake" }
+
+func (m *fakeModel) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.prompts = append(m.prompts, req.Contents[len(req.Contents)-1].Parts[0].Text)
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.response, genai.RoleModel)}, nil)
+	}
+}
+
+func TestLLMScorer(t *testing.T) {
+	m := &fakeModel{response: `[{"id": 1, "score": 0.9}, {"id": 2, "score": 1.7}, {"id": 9, "score": 1}]`}
+	s := &LLMScorer{Model: m, Prompts: prompts.Default()}
+	scores, err := s.Score(context.Background(), "EV adoption", []models.CandidateStatistic{
+		{Name: "EV sales", ValueText: "14 million", Excerpt: "EV sales reached 14 million"},
+		{Name: "EV share", Value: 18, Unit: "%", Excerpt: "18% of new cars"},
+		{Name: "Page views", Value: 1200, Excerpt: "1,200 views"},
+	})
+	if err != nil {
+		t.Fatalf("Score: %v", err)
+	}
+	// Scores are clamped to 0-1; candidates the model leaves out score 0
+	if fmt.Sprint(scores) != "[0.9 1 0]" {
+		t.Errorf("scores = %v", scores)
+	}
+	if len(m.prompts) != 1 {
+		t.Fatalf("got %d prompts, want 1", len(m.prompts))
+	}
+	for _, want := range []string{`research topic "EV adoption"`, `[1] EV sales: 14 million ("EV sales reached 14 million")`, "[3] Page views: 1200"} {
+		if !strings.Contains(m.prompts[0], want) {
+			t.Errorf("prompt lacks %q:\n%s", want, m.prompts[0])
+		}
+	}
+}
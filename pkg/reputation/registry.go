@@ -0,0 +1,359 @@
+package reputation
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/stats-agent-team/pkg/config"
+	"github.com/grokify/stats-agent-team/pkg/models"
+)
+
+// Tier classifies how trustworthy a source domain is for statistics
+type Tier string
+
+const (
+	TierPrimaryGovernment Tier = "primary_government" // Government agencies and intergovernmental organizations
+	TierAcademic          Tier = "academic"           // Universities and academic research institutes
+	TierJournal           Tier = "journal"            // Peer-reviewed journals and publishers
+	TierNGO               Tier = "ngo"                // Research organizations, think tanks and pollsters
+	TierMedia             Tier = "media"              // News organizations
+	TierBlocked           Tier = "blocked"            // Never used as a source
+	TierAllowed           Tier = "allowed"            // Explicitly allowed for a single request
+	TierUnknown           Tier = "unknown"            // Not listed in the registry
+)
+
+// tierOrder lists tiers from most to least trusted, for prompts and descriptions
+var tierOrder = []Tier{TierPrimaryGovernment, TierAcademic, TierJournal, TierNGO, TierMedia}
+
+// tierDescriptions are human-readable labels used when describing the registry in prompts
+var tierDescriptions = map[Tier]string{
+	TierPrimaryGovernment: "Government agencies and international organizations",
+	TierAcademic:          "Academic institutions",
+	TierJournal:           "Peer-reviewed journals",
+	TierNGO:               "Research organizations and pollsters",
+	TierMedia:             "News media",
+}
+
+//go:embed default.yaml
+var defaultRegistry []byte
+
+// File is the on-disk registry format (YAML or JSON)
+type File struct {
+	ReputableScore float64           `json:"reputable_score" yaml:"reputable_score"`
+	Scores         map[Tier]float64  `json:"scores" yaml:"scores"`
+	Tiers          map[Tier][]string `json:"tiers" yaml:"tiers"`
+}
+
+// Registry classifies source domains into reputation tiers
+type Registry struct {
+	reputableScore float64
+	scores         map[Tier]float64
+	domains        map[string]Tier // Registrable domains and hosts (e.g., "who.int")
+	suffixes       map[string]Tier // Public suffix rules (e.g., "gov", "ac.uk")
+	allow          map[string]bool // Per-request allowed domains
+	deny           map[string]bool // Per-request denied domains
+}
+
+// Default returns the registry embedded in the binary
+func Default() *Registry {
+	r, err := parse(defaultRegistry, "default.yaml")
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded reputation registry: %v", err))
+	}
+	return r
+}
+
+// Load reads a registry from a YAML or JSON file
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reputation registry: %w", err)
+	}
+	return parse(data, path)
+}
+
+// LoadFromConfig loads the registry named by REPUTATION_FILE, or the embedded default
+func LoadFromConfig(cfg *config.Config) (*Registry, error) {
+	if cfg.ReputationFile == "" {
+		return Default(), nil
+	}
+	return Load(cfg.ReputationFile)
+}
+
+// parse decodes a registry file, choosing JSON or YAML by file extension
+func parse(data []byte, name string) (*Registry, error) {
+	var f File
+	var err error
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reputation registry %s: %w", name, err)
+	}
+
+	r := &Registry{
+		reputableScore: f.ReputableScore,
+		scores:         map[Tier]float64{TierUnknown: 0.3, TierBlocked: 0, TierAllowed: 0.8},
+		domains:        map[string]Tier{},
+		suffixes:       map[string]Tier{},
+	}
+	if r.reputableScore == 0 {
+		r.reputableScore = 0.7
+	}
+	for tier, score := range f.Scores {
+		r.scores[tier] = score
+	}
+	for tier, entries := range f.Tiers {
+		if _, ok := r.scores[tier]; !ok {
+			return nil, fmt.Errorf("reputation registry %s: tier %q has no score", name, tier)
+		}
+		for _, entry := range entries {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+			if rule, ok := strings.CutPrefix(entry, "."); ok {
+				r.suffixes[rule] = tier
+			} else if entry != "" {
+				r.domains[entry] = tier
+			}
+		}
+	}
+	return r, nil
+}
+
+// WithOverrides returns a copy of the registry with per-request allow and deny lists.
+// Denied domains are treated as blocked; allowed domains count as reputable, even
+// if the registry blocks them. A domain on both lists is denied.
+func (r *Registry) WithOverrides(allow, deny []string) *Registry {
+	if len(allow) == 0 && len(deny) == 0 {
+		return r
+	}
+	clone := *r
+	clone.allow = toSet(allow, r.allow)
+	clone.deny = toSet(deny, r.deny)
+	return &clone
+}
+
+// Lookup returns the tier of a domain or URL
+func (r *Registry) Lookup(domainOrURL string) Tier {
+	host := normalizeHost(domainOrURL)
+	if host == "" {
+		return TierUnknown
+	}
+
+	if matchesDomain(host, r.deny) {
+		return TierBlocked
+	}
+
+	tier := r.lookupRegistered(host)
+	if !r.isReputableTier(tier) && matchesDomain(host, r.allow) {
+		return TierAllowed
+	}
+	return tier
+}
+
+// lookupRegistered finds the most specific registry entry for a host: listed domains
+// from the host up to its registrable domain (eTLD+1) first, then public suffix rules.
+// A host that is itself a public suffix ("gov") matches no suffix rule.
+func (r *Registry) lookupRegistered(host string) Tier {
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		if tier, ok := r.domains[host]; ok {
+			return tier
+		}
+		return TierUnknown
+	}
+	for name := host; ; {
+		if tier, ok := r.domains[name]; ok {
+			return tier
+		}
+		if name == registrable {
+			break
+		}
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+
+	suffix, _ := publicsuffix.PublicSuffix(host)
+	for name := suffix; name != ""; {
+		if tier, ok := r.suffixes[name]; ok {
+			return tier
+		}
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return TierUnknown
+}
+
+// Score returns the reputation score (0-1) of a domain or URL
+func (r *Registry) Score(domainOrURL string) float64 {
+	return r.scores[r.Lookup(domainOrURL)]
+}
+
+// IsReputable reports whether a domain or URL scores at or above the reputable threshold
+func (r *Registry) IsReputable(domainOrURL string) bool {
+	return r.isReputableTier(r.Lookup(domainOrURL))
+}
+
+// IsBlocked reports whether a domain or URL must never be used as a source
+func (r *Registry) IsBlocked(domainOrURL string) bool {
+	return r.Lookup(domainOrURL) == TierBlocked
+}
+
+func (r *Registry) isReputableTier(tier Tier) bool {
+	return tier != TierBlocked && r.scores[tier] >= r.reputableScore
+}
+
+// Describe renders the reputable tiers and their domains as prose for LLM prompts
+func (r *Registry) Describe() string {
+	var b strings.Builder
+	for _, tier := range tierOrder {
+		if !r.isReputableTier(tier) {
+			continue
+		}
+		entries := r.entries(tier)
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", tierDescriptions[tier], strings.Join(entries, ", "))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// entries returns the suffix rules and domains listed for a tier, suffix rules first
+func (r *Registry) entries(tier Tier) []string {
+	var suffixes, domains []string
+	for rule, t := range r.suffixes {
+		if t == tier {
+			suffixes = append(suffixes, "."+rule+" domains")
+		}
+	}
+	for domain, t := range r.domains {
+		if t == tier {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(suffixes)
+	sort.Strings(domains)
+	return append(suffixes, domains...)
+}
+
+// SiteFilters returns up to n domains and suffix rules for site: search filters,
+// taking entries round-robin from the reputable tiers so the most trusted tiers
+// come first and each is represented. Broad suffix rules (e.g., "gov") precede
+// individual domains within a tier.
+func (r *Registry) SiteFilters(n int) []string {
+	var lists [][]string
+	for _, tier := range tierOrder {
+		if !r.isReputableTier(tier) {
+			continue
+		}
+		var suffixes, domains []string
+		for rule, t := range r.suffixes {
+			if t == tier {
+				suffixes = append(suffixes, rule)
+			}
+		}
+		for domain, t := range r.domains {
+			if t == tier {
+				domains = append(domains, domain)
+			}
+		}
+		sort.Slice(suffixes, func(i, j int) bool {
+			if len(suffixes[i]) != len(suffixes[j]) {
+				return len(suffixes[i]) < len(suffixes[j])
+			}
+			return suffixes[i] < suffixes[j]
+		})
+		sort.Strings(domains)
+		if entries := append(suffixes, domains...); len(entries) > 0 {
+			lists = append(lists, entries)
+		}
+	}
+
+	var filters []string
+	for i := 0; len(filters) < n; i++ {
+		added := false
+		for _, entries := range lists {
+			if i < len(entries) && len(filters) < n {
+				filters = append(filters, entries[i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return filters
+}
+
+// RankStatistics annotates each statistic with its source tier and stably sorts the
+// slice so statistics from the most reputable sources come first
+func (r *Registry) RankStatistics(stats []models.Statistic) {
+	scores := make(map[string]float64, len(stats))
+	for i := range stats {
+		tier := r.Lookup(stats[i].SourceURL)
+		stats[i].SourceTier = string(tier)
+		scores[stats[i].SourceURL] = r.scores[tier]
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return scores[stats[i].SourceURL] > scores[stats[j].SourceURL]
+	})
+}
+
+// normalizeHost extracts a lowercase host name from a domain or URL
+func normalizeHost(domainOrURL string) string {
+	s := strings.ToLower(strings.TrimSpace(domainOrURL))
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil {
+			s = u.Hostname()
+		}
+	} else if i := strings.IndexAny(s, "/:"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, ".")
+}
+
+// matchesDomain reports whether host equals or is a subdomain of an entry in set
+func matchesDomain(host string, set map[string]bool) bool {
+	for name := host; name != ""; {
+		if set[name] {
+			return true
+		}
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return false
+}
+
+// toSet merges normalized domains into a copy of base
+func toSet(domains []string, base map[string]bool) map[string]bool {
+	set := make(map[string]bool, len(base)+len(domains))
+	for d := range base {
+		set[d] = true
+	}
+	for _, d := range domains {
+		if host := normalizeHost(d); host != "" {
+			set[strings.TrimPrefix(host, ".")] = true
+		}
+	}
+	return set
+}
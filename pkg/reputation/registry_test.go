@@ -0,0 +1,150 @@
+package reputation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grokify/stats-agent-team/pkg/models"
+)
+
+func TestLookup(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		input string
+		want  Tier
+	}{
+		{"cdc.gov", TierPrimaryGovernment},
+		{"https://www.cdc.gov/nchs/data.htm", TierPrimaryGovernment},
+		{"statcan.gc.ca", TierPrimaryGovernment},
+		{"www150.statcan.gc.ca/n1/en/type/data", TierPrimaryGovernment},
+		{"ons.gov.uk", TierPrimaryGovernment},
+		{"data.worldbank.org", TierPrimaryGovernment},
+		{"HTTPS://WWW.WHO.INT:443/news", TierPrimaryGovernment},
+		{"who.int.", TierPrimaryGovernment},
+		{"mit.edu", TierAcademic},
+		{"ox.ac.uk", TierAcademic},
+		{"www.nature.com", TierJournal},
+		{"quora.com", TierBlocked},
+		{"notagov.com.evil", TierUnknown},
+		{"cdc.gov.evil.com", TierUnknown},
+		{"notwho.int", TierUnknown},
+		{"gov", TierUnknown},
+		{"example.com", TierUnknown},
+		{"", TierUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := r.Lookup(tt.input); got != tt.want {
+				t.Errorf("Lookup(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		allow, deny []string
+		input       string
+		want        Tier
+		reputable   bool
+	}{
+		{"allow unknown", []string{"example.com"}, nil, "blog.example.com", TierAllowed, true},
+		{"allow beats blocked tier", []string{"quora.com"}, nil, "https://www.quora.com/q", TierAllowed, true},
+		{"allow keeps a better tier", []string{"cdc.gov"}, nil, "cdc.gov", TierPrimaryGovernment, true},
+		{"deny beats reputable tier", nil, []string{"cdc.gov"}, "www.cdc.gov", TierBlocked, false},
+		{"deny subdomain only", nil, []string{"data.worldbank.org"}, "worldbank.org", TierPrimaryGovernment, true},
+		{"deny beats allow", []string{"example.com"}, []string{"example.com"}, "example.com", TierBlocked, false},
+		{"deny written as URL", nil, []string{"https://WWW.Nature.com/"}, "www.nature.com", TierBlocked, false},
+		{"lookalike not allowed", []string{"example.com"}, nil, "example.com.evil", TierUnknown, false},
+	}
+	base := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := base.WithOverrides(tt.allow, tt.deny)
+			if got := r.Lookup(tt.input); got != tt.want {
+				t.Errorf("Lookup(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+			if got := r.IsReputable(tt.input); got != tt.reputable {
+				t.Errorf("IsReputable(%q) = %v, want %v", tt.input, got, tt.reputable)
+			}
+		})
+	}
+	if base.Lookup("quora.com") != TierBlocked || base.Lookup("cdc.gov") != TierPrimaryGovernment {
+		t.Error("WithOverrides changed the base registry")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	r, err := Load(write("registry.yaml", "scores:\n  ngo: 0.75\ntiers:\n  ngo:\n    - Example.org\n    - .example\n  blocked:\n    - spam.com\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for input, want := range map[string]Tier{"www.example.org": TierNGO, "site.example": TierNGO, "spam.com": TierBlocked, "cdc.gov": TierUnknown} {
+		if got := r.Lookup(input); got != want {
+			t.Errorf("Lookup(%q) = %s, want %s", input, got, want)
+		}
+	}
+	if !r.IsReputable("example.org") || r.Score("example.org") != 0.75 {
+		t.Errorf("example.org scores %v, reputable %v", r.Score("example.org"), r.IsReputable("example.org"))
+	}
+
+	if _, err := Load(write("registry.json", `{"tiers": {"academic": [".edu"]}, "scores": {"academic": 0.9}}`)); err != nil {
+		t.Errorf("Load JSON: %v", err)
+	}
+
+	errorTests := []struct {
+		name, file, content, want string
+	}{
+		{"malformed yaml", "bad.yaml", "tiers:\n  ngo: [example.org\n", "failed to parse"},
+		{"malformed json", "bad.json", `{"tiers": `, "failed to parse"},
+		{"tier without score", "noscore.yaml", "tiers:\n  think_tank:\n    - example.org\n", `tier "think_tank" has no score`},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(write(tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+}
+
+func TestRankStatistics(t *testing.T) {
+	stats := []models.Statistic{
+		{Name: "a", SourceURL: "https://example.com/a"},
+		{Name: "b", SourceURL: "https://www.nature.com/b"},
+		{Name: "c", SourceURL: "https://www.census.gov/c"},
+		{Name: "d", SourceURL: "https://example.com/d"},
+	}
+	Default().RankStatistics(stats)
+	var got []string
+	for _, s := range stats {
+		got = append(got, s.Name+":"+s.SourceTier)
+	}
+	want := "c:primary_government b:journal a:unknown d:unknown"
+	if strings.Join(got, " ") != want {
+		t.Errorf("ranked %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestSiteFilters(t *testing.T) {
+	filters := Default().SiteFilters(4)
+	if len(filters) != 4 || filters[0] != "gov" || filters[1] != "edu" {
+		t.Errorf("SiteFilters(4) = %v", filters)
+	}
+}
@@ -0,0 +1,252 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/grokify/stats-agent-team/pkg/htmltext"
+	"github.com/grokify/stats-agent-team/pkg/pdftext"
+)
+
+// BM25 parameters (standard Okapi defaults)
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// corpusDisplayLink is shown as the domain of local corpus results
+const corpusDisplayLink = "local-corpus"
+
+// CorpusProvider runs BM25 keyword search over a local directory of HTML, Markdown,
+// plain text and PDF documents. Results link to the documents with file:// URLs.
+type CorpusProvider struct {
+	dir       string
+	docs      []corpusDoc
+	docFreq   map[string]int
+	avgLength float64
+}
+
+// corpusDoc is an indexed document
+type corpusDoc struct {
+	url    string
+	title  string
+	text   string
+	terms  map[string]int
+	length int
+}
+
+// NewCorpusProvider indexes every supported document under dir
+func NewCorpusProvider(dir string) (*CorpusProvider, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("corpus directory: %w", err)
+	}
+
+	p := &CorpusProvider{dir: root, docFreq: map[string]int{}}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !IsCorpusFile(path) {
+			return nil
+		}
+		doc, err := loadCorpusDoc(path)
+		if err != nil {
+			log.Printf("Search: skipping corpus document %s: %v", path, err)
+			return nil
+		}
+		p.add(doc)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index corpus: %w", err)
+	}
+	if len(p.docs) == 0 {
+		return nil, fmt.Errorf("no HTML, Markdown, text or PDF documents found in %s", root)
+	}
+
+	total := 0
+	for _, doc := range p.docs {
+		total += doc.length
+	}
+	p.avgLength = float64(total) / float64(len(p.docs))
+	log.Printf("Search: indexed %d corpus documents from %s", len(p.docs), root)
+	return p, nil
+}
+
+// Name returns "corpus"
+func (p *CorpusProvider) Name() string {
+	return "corpus"
+}
+
+// Search ranks corpus documents against the query with BM25
+func (p *CorpusProvider) Search(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	queryTerms := uniqueTerms(tokenize(params.Query))
+
+	type scored struct {
+		doc   *corpusDoc
+		score float64
+	}
+	var matches []scored
+	n := float64(len(p.docs))
+	for i := range p.docs {
+		doc := &p.docs[i]
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := float64(doc.terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(p.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(doc.length)/p.avgLength)
+			score += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+		if score > 0 {
+			matches = append(matches, scored{doc: doc, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	start, end := params.pageWindow(len(matches))
+	matches = matches[start:end]
+
+	results := make([]SearchResult, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, SearchResult{
+			Title:       m.doc.title,
+			URL:         m.doc.url,
+			Snippet:     snippet(m.doc.text, queryTerms),
+			DisplayLink: corpusDisplayLink,
+		})
+	}
+	return &SearchResponse{Results: results, Total: len(results)}, nil
+}
+
+// add indexes a document
+func (p *CorpusProvider) add(doc corpusDoc) {
+	tokens := tokenize(doc.text)
+	doc.terms = make(map[string]int, len(tokens)/2)
+	for _, t := range tokens {
+		doc.terms[t]++
+	}
+	doc.length = len(tokens)
+	for t := range doc.terms {
+		p.docFreq[t]++
+	}
+	p.docs = append(p.docs, doc)
+}
+
+// IsCorpusFile reports whether a file has an extension the corpus provider indexes
+func IsCorpusFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm", ".md", ".markdown", ".txt", ".pdf":
+		return true
+	}
+	return false
+}
+
+// loadCorpusDoc reads a document and extracts its title and plain text
+func loadCorpusDoc(path string) (corpusDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return corpusDoc{}, err
+	}
+
+	doc := corpusDoc{
+		url:   (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(),
+		title: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		page, err := htmltext.Extract(data, "text/html")
+		if err != nil {
+			return corpusDoc{}, err
+		}
+		if page.Title != "" {
+			doc.title = page.Title
+		}
+		doc.text = page.Text
+	case ".pdf":
+		text, err := pdftext.Text(data)
+		if err != nil {
+			return corpusDoc{}, err
+		}
+		doc.text = text
+	default:
+		doc.text = string(data)
+		if title := markdownTitle(doc.text); title != "" {
+			doc.title = title
+		}
+	}
+	return doc, nil
+}
+
+// markdownTitle returns the first level-one heading of a Markdown document
+func markdownTitle(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// tokenize lowercases text and splits it into letter/digit terms
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// snippet returns a window of text around the first occurrence of a query term
+func snippet(text string, terms []string) string {
+	const width = 240
+	words := strings.Fields(text)
+	center := -1
+	for i, w := range words {
+		for _, t := range tokenize(w) {
+			for _, q := range terms {
+				if t == q {
+					center = i
+					break
+				}
+			}
+		}
+		if center >= 0 {
+			break
+		}
+	}
+
+	// Start a few words before the match so the snippet reads naturally
+	start := max(center-12, 0)
+	end, length := start, 0
+	for end < len(words) && length < width {
+		length += len(words[end]) + 1
+		end++
+	}
+	return strings.Join(words[start:end], " ")
+}
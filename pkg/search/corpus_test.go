@@ -0,0 +1,87 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCorpusProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ev-outlook.html"), `<html><head><title>Global EV Outlook</title></head><body>
+		<nav>Home Reports Electric</nav>
+		<main><p>Electric car sales neared 14 million in 2023. Electric cars were 18% of all cars sold.</p></main></body></html>`)
+	writeFile(t, filepath.Join(dir, "notes", "solar.md"), "# Solar capacity\n\nSolar capacity additions reached 440 GW in 2023, while car sales were flat.\n")
+	writeFile(t, filepath.Join(dir, "wind.txt"), "Wind power generation grew 10% in 2023.")
+	writeFile(t, filepath.Join(dir, "data.csv"), "electric,sales\n14,2023\n")
+	writeFile(t, filepath.Join(dir, "broken.pdf"), "not a pdf")
+
+	p, err := NewCorpusProvider(dir)
+	if err != nil {
+		t.Fatalf("NewCorpusProvider: %v", err)
+	}
+	if len(p.docs) != 3 {
+		t.Fatalf("indexed %d documents, want 3 (CSV and broken PDF skipped)", len(p.docs))
+	}
+
+	tests := []struct {
+		name       string
+		params     SearchParams
+		wantTitles []string
+	}{
+		{"best match first", SearchParams{Query: "electric car sales"}, []string{"Global EV Outlook", "Solar capacity"}},
+		{"case and punctuation ignored", SearchParams{Query: "SOLAR, capacity?"}, []string{"Solar capacity"}},
+		{"text file titled by name", SearchParams{Query: "wind"}, []string{"wind"}},
+		{"shorter documents rank higher", SearchParams{Query: "2023"}, []string{"wind", "Solar capacity", "Global EV Outlook"}},
+		{"boilerplate not indexed", SearchParams{Query: "home reports"}, nil},
+		{"no match", SearchParams{Query: "hydrogen"}, nil},
+		{"paged", SearchParams{Query: "electric car sales", NumResults: 1, Page: 2}, []string{"Solar capacity"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Search(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			var titles []string
+			for _, r := range resp.Results {
+				titles = append(titles, r.Title)
+				if !strings.HasPrefix(r.URL, "file://") || r.DisplayLink != corpusDisplayLink {
+					t.Errorf("result %s has URL %s, display link %s", r.Title, r.URL, r.DisplayLink)
+				}
+			}
+			if strings.Join(titles, ", ") != strings.Join(tt.wantTitles, ", ") {
+				t.Errorf("got %q, want %q", titles, tt.wantTitles)
+			}
+			if resp.Total != len(resp.Results) {
+				t.Errorf("Total = %d with %d results", resp.Total, len(resp.Results))
+			}
+		})
+	}
+}
+
+func TestCorpusSnippet(t *testing.T) {
+	text := strings.Repeat("filler ", 100) + "Electric car sales neared 14 million. " + strings.Repeat("more ", 100)
+	got := snippet(text, []string{"sales"})
+	if !strings.Contains(got, "Electric car sales neared 14 million.") {
+		t.Errorf("snippet lacks the match: %q", got)
+	}
+	if !strings.HasPrefix(got, "filler") || len(got) > 260 {
+		t.Errorf("snippet %q does not start a few words before the match", got)
+	}
+	if got := snippet("Short text.", []string{"absent"}); got != "Short text." {
+		t.Errorf("snippet without a match = %q", got)
+	}
+}
+
+func TestNewCorpusProviderErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "data.csv"), "a,b")
+	if _, err := NewCorpusProvider(dir); err == nil || !strings.Contains(err.Error(), "no HTML") {
+		t.Errorf("NewCorpusProvider without documents = %v", err)
+	}
+	if _, err := NewCorpusProvider(filepath.Join(dir, "missing")); err == nil {
+		t.Error("NewCorpusProvider of a missing directory succeeded")
+	}
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/grokify/stats-agent-team/pkg/prompts"
+)
+
+// listMarker matches bullets and numbering at the start of a line
+var listMarker = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s*`)
+
+// LLMQueryExpander asks an LLM for alternative search queries
+type LLMQueryExpander struct {
+	model   model.LLM
+	prompts *prompts.Registry
+	variant string
+}
+
+// NewLLMQueryExpander creates a query expander backed by an LLM, prompted with
+// the given variant of the search.expand prompt ("" for the default)
+func NewLLMQueryExpander(llm model.LLM, registry *prompts.Registry, variant string) *LLMQueryExpander {
+	return &LLMQueryExpander{model: llm, prompts: registry, variant: variant}
+}
+
+// ExpandQueries returns up to n web search queries likely to surface statistics on the topic
+func (e *LLMQueryExpander) ExpandQueries(ctx context.Context, topic string, n int) ([]string, error) {
+	prompt, _, err := e.prompts.Render(prompts.SearchExpand, e.variant, struct {
+		N     int
+		Topic string
+	}{n, topic})
+	if err != nil {
+		return nil, err
+	}
+
+	llmReq := &model.LLMRequest{
+		Contents: genai.Text(prompt),
+	}
+
+	var response string
+	for llmResp, err := range e.model.GenerateContent(ctx, llmReq, false) {
+		if err != nil {
+			return nil, fmt.Errorf("LLM generation failed: %w", err)
+		}
+		if llmResp.Content != nil && llmResp.Content.Parts != nil {
+			for _, part := range llmResp.Content.Parts {
+				if part.Text != "" {
+					response += part.Text
+				}
+			}
+		}
+	}
+
+	var queries []string
+	for _, line := range strings.Split(response, "\n") {
+		// Strip list markers and quotes the model adds despite instructions
+		line = listMarker.ReplaceAllString(strings.TrimSpace(line), "")
+		line = strings.Trim(line, "\"'` ")
+		if line == "" || strings.HasSuffix(line, ":") {
+			continue
+		}
+		queries = append(queries, line)
+		if len(queries) == n {
+			break
+		}
+	}
+	return queries, nil
+}
@@ -0,0 +1,146 @@
+package search
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryWithOperators returns the query with Google-style operators for the site and
+// publication date filters (site:, -site:, after:, before:). Providers backed by
+// Google or engines with the same syntax use it to apply the filters natively.
+func (p SearchParams) QueryWithOperators() string {
+	parts := []string{p.Query}
+	switch len(p.SiteInclude) {
+	case 0:
+	case 1:
+		parts = append(parts, "site:"+p.SiteInclude[0])
+	default:
+		sites := make([]string, len(p.SiteInclude))
+		for i, site := range p.SiteInclude {
+			sites[i] = "site:" + site
+		}
+		parts = append(parts, "("+strings.Join(sites, " OR ")+")")
+	}
+	for _, site := range p.SiteExclude {
+		parts = append(parts, "-site:"+site)
+	}
+	if !p.PublishedAfter.IsZero() {
+		parts = append(parts, "after:"+p.PublishedAfter.Format("2006-01-02"))
+	}
+	if !p.PublishedBefore.IsZero() {
+		parts = append(parts, "before:"+p.PublishedBefore.Format("2006-01-02"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// filterResults drops results outside the requested sites or publication dates.
+// Results without a known publication date are kept, since they cannot be shown
+// to fall outside the range. It returns the number of results dropped.
+func filterResults(resp *SearchResponse, params SearchParams) int {
+	kept := resp.Results[:0]
+	for _, r := range resp.Results {
+		if !matchesSites(r.URL, params.SiteInclude, params.SiteExclude) {
+			continue
+		}
+		if published, err := time.Parse("2006-01-02", r.PublishedDate); err == nil {
+			if !params.PublishedAfter.IsZero() && published.Before(params.PublishedAfter) {
+				continue
+			}
+			if !params.PublishedBefore.IsZero() && !published.Before(params.PublishedBefore) {
+				continue
+			}
+		}
+		kept = append(kept, r)
+	}
+	dropped := len(resp.Results) - len(kept)
+	resp.Results = kept
+	resp.Total = len(kept)
+	return dropped
+}
+
+// matchesSites reports whether a web URL passes the site include and exclude lists.
+// Domains match themselves and their subdomains; non-web URLs (e.g., local corpus
+// files) are never filtered.
+func matchesSites(rawURL string, include, exclude []string) bool {
+	if len(include) == 0 && len(exclude) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, site := range exclude {
+		if hostMatches(host, site) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, site := range include {
+		if hostMatches(host, site) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostMatches reports whether host equals site or is a subdomain of it
+func hostMatches(host, site string) bool {
+	site = strings.Trim(strings.ToLower(strings.TrimSpace(site)), ".")
+	return site != "" && (host == site || strings.HasSuffix(host, "."+site))
+}
+
+// relativeDate matches relative SERP dates such as "3 days ago"
+var relativeDate = regexp.MustCompile(`^(\d+)\s+(minute|hour|day|week|month|year)s?\s+ago$`)
+
+// resultDateLayouts are the absolute date formats search providers return
+var resultDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2006",
+}
+
+// normalizeResultDate converts a provider's publication date to YYYY-MM-DD, or
+// returns "" when the date cannot be parsed
+func normalizeResultDate(raw string, now time.Time) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	for _, layout := range resultDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+
+	m := relativeDate.FindStringSubmatch(strings.ToLower(raw))
+	if m == nil {
+		return ""
+	}
+	n, _ := strconv.Atoi(m[1])
+	var t time.Time
+	switch m[2] {
+	case "minute", "hour":
+		t = now
+	case "day":
+		t = now.AddDate(0, 0, -n)
+	case "week":
+		t = now.AddDate(0, 0, -7*n)
+	case "month":
+		t = now.AddDate(0, -n, 0)
+	case "year":
+		t = now.AddDate(-n, 0, 0)
+	}
+	return t.Format("2006-01-02")
+}
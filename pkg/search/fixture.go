@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/grokify/metaserp"
+)
+
+// defaultFixture is replayed for queries without a fixture of their own
+const defaultFixture = "default"
+
+// FixtureProvider replays recorded search results from a directory of JSON files.
+// Each file holds a metaserp normalized search result and is named after the query
+// it answers (see FixtureKey), so runs are reproducible without API keys.
+type FixtureProvider struct {
+	dir string
+}
+
+// NewFixtureProvider creates a provider that replays fixtures from dir
+func NewFixtureProvider(dir string) (*FixtureProvider, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fixture directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixture directory %s is not a directory", dir)
+	}
+	return &FixtureProvider{dir: dir}, nil
+}
+
+// Name returns "fixture"
+func (p *FixtureProvider) Name() string {
+	return "fixture"
+}
+
+// Search returns the recorded results for the query, falling back to default.json
+func (p *FixtureProvider) Search(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	var data []byte
+	var err error
+	for _, key := range []string{FixtureKey(params.Query), defaultFixture} {
+		data, err = os.ReadFile(filepath.Join(p.dir, key+".json"))
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no fixture for query %q (expected %s.json in %s)", params.Query, FixtureKey(params.Query), p.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var result metaserp.NormalizedSearchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture for query %q: %w", params.Query, err)
+	}
+
+	resp := fromNormalized(&result)
+	start, end := params.pageWindow(len(resp.Results))
+	resp.Results = resp.Results[start:end]
+	resp.Total = len(resp.Results)
+	return resp, nil
+}
+
+// FixtureKey returns the fixture file name (without .json) for a query: the
+// lowercased query with runs of other characters replaced by hyphens
+// (e.g., "climate change statistics" -> "climate-change-statistics")
+func FixtureKey(query string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(query) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	key := b.String()
+	if runes := []rune(key); len(runes) > 120 {
+		key = strings.TrimRight(string(runes[:120]), "-")
+	}
+	if key == "" {
+		return defaultFixture
+	}
+	return key
+}
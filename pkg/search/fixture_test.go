@@ -0,0 +1,119 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grokify/metaserp"
+)
+
+func TestFixtureKey(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"climate change statistics", "climate-change-statistics"},
+		{"  EV Sales, 2023!  ", "ev-sales-2023"},
+		{"site:gov unemployment -opinion", "site-gov-unemployment-opinion"},
+		{"énergie solaire", "énergie-solaire"},
+		{"", "default"},
+		{"!!!", "default"},
+		{strings.Repeat("a ", 100), strings.TrimRight(strings.Repeat("a-", 60), "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := FixtureKey(tt.query); got != tt.want {
+				t.Errorf("FixtureKey(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixtureProvider(t *testing.T) {
+	dir := t.TempDir()
+	// Fixtures recorded by a live provider are replayed
+	recorded := &metaserp.NormalizedSearchResult{}
+	for i := 1; i <= 25; i++ {
+		recorded.OrganicResults = append(recorded.OrganicResults, metaserp.OrganicResult{
+			Position: i,
+			Title:    fmt.Sprintf("Result %d", i),
+			Link:     fmt.Sprintf("https://example.org/%d", i),
+		})
+	}
+	if err := recordFixture(dir, "EV sales 2023", recorded); err != nil {
+		t.Fatalf("recordFixture: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "default.json"), `{"organic_results": [{"title": "Default", "link": "https://example.org/default"}]}`)
+	writeFile(t, filepath.Join(dir, "broken.json"), `{"organic_results": [`)
+
+	p, err := NewFixtureProvider(dir)
+	if err != nil {
+		t.Fatalf("NewFixtureProvider: %v", err)
+	}
+	tests := []struct {
+		name      string
+		params    SearchParams
+		wantFirst string
+		wantLen   int
+		wantErr   string
+	}{
+		{"recorded query", SearchParams{Query: "ev sales 2023", NumResults: 10}, "https://example.org/1", 10, ""},
+		{"second page", SearchParams{Query: "EV sales 2023", NumResults: 10, Page: 2}, "https://example.org/11", 10, ""},
+		{"last page", SearchParams{Query: "EV sales 2023", NumResults: 10, Page: 3}, "https://example.org/21", 5, ""},
+		{"past the end", SearchParams{Query: "EV sales 2023", NumResults: 10, Page: 4}, "", 0, ""},
+		{"falls back to default", SearchParams{Query: "unrecorded", NumResults: 10}, "https://example.org/default", 1, ""},
+		{"invalid fixture", SearchParams{Query: "broken"}, "", 0, "failed to parse fixture"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Search(context.Background(), tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(resp.Results) != tt.wantLen || resp.Total != tt.wantLen {
+				t.Fatalf("got %d results (total %d), want %d", len(resp.Results), resp.Total, tt.wantLen)
+			}
+			if tt.wantLen > 0 && resp.Results[0].URL != tt.wantFirst {
+				t.Errorf("first result %s, want %s", resp.Results[0].URL, tt.wantFirst)
+			}
+		})
+	}
+
+	if err := os.Remove(filepath.Join(dir, "default.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Search(context.Background(), SearchParams{Query: "unrecorded"}); err == nil || !strings.Contains(err.Error(), "unrecorded.json") {
+		t.Errorf("missing fixture error = %v", err)
+	}
+}
+
+func TestNewFixtureProviderErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "default.json")
+	writeFile(t, file, "{}")
+	for _, path := range []string{filepath.Join(dir, "missing"), file} {
+		if _, err := NewFixtureProvider(path); err == nil {
+			t.Errorf("NewFixtureProvider(%s) succeeded", path)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
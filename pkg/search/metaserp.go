@@ -0,0 +1,187 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/metaserp"
+	"github.com/grokify/metaserp/client"
+)
+
+// metaserpMaxResults is the most results the SERP APIs return for a query
+const metaserpMaxResults = 100
+
+// metaserpCacheSize bounds how many full result lists are kept for paging
+const metaserpCacheSize = 256
+
+// metaserpCacheTTL is how long a full result list serves later pages
+const metaserpCacheTTL = 10 * time.Minute
+
+// MetaserpProvider searches Google through a metaserp engine (serper or serpapi)
+type MetaserpProvider struct {
+	engine    string
+	search    func(context.Context, metaserp.SearchParams) (*metaserp.NormalizedSearchResult, error)
+	recordDir string // When set, normalized results are saved as fixtures
+
+	mu    sync.Mutex
+	pages map[string]cachedResults // Full result lists by query and locale, for later pages
+}
+
+// cachedResults is a full result list fetched for paging
+type cachedResults struct {
+	resp    *SearchResponse
+	fetched time.Time
+}
+
+// NewMetaserpProvider creates a provider for a metaserp engine. If recordDir is
+// non-empty, every response is also written there for the fixture provider.
+func NewMetaserpProvider(engine, recordDir string) (*MetaserpProvider, error) {
+	c, err := client.NewWithEngine(engine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search client: %w", err)
+	}
+	return &MetaserpProvider{engine: engine, search: c.SearchNormalized, recordDir: recordDir}, nil
+}
+
+// Name returns the metaserp engine name
+func (p *MetaserpProvider) Name() string {
+	return p.engine
+}
+
+// Search performs a normalized search using metaserp. The APIs have no page
+// parameter, so the first request for a later page fetches all the results a
+// query has (up to 100) at once and serves that page and the ones after it
+// from them.
+func (p *MetaserpProvider) Search(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	numResults := params.NumResults
+	if numResults <= 0 {
+		numResults = 10
+	}
+	offset := max(params.Page-1, 0) * numResults
+	if offset >= metaserpMaxResults {
+		return &SearchResponse{}, nil
+	}
+	if offset == 0 {
+		return p.fetch(ctx, params, numResults)
+	}
+
+	key := strings.Join([]string{params.QueryWithOperators(), params.Language, params.Country}, "\x00")
+	all, ok := p.cached(key)
+	if !ok {
+		var err error
+		if all, err = p.fetch(ctx, params, metaserpMaxResults); err != nil {
+			return nil, err
+		}
+		p.store(key, all)
+	}
+
+	results := all.Results[min(offset, len(all.Results)):]
+	results = results[:min(numResults, len(results))]
+	return &SearchResponse{Results: append([]SearchResult(nil), results...), Total: len(results)}, nil
+}
+
+// fetch requests the first num results for a query
+func (p *MetaserpProvider) fetch(ctx context.Context, params SearchParams, num int) (*SearchResponse, error) {
+	result, err := p.search(ctx, metaserp.SearchParams{
+		Query:      params.QueryWithOperators(),
+		NumResults: num,
+		Language:   params.Language,
+		Country:    params.Country,
+	})
+	if err != nil {
+		return nil, metaserpError(p.engine, err)
+	}
+
+	if p.recordDir != "" {
+		if err := recordFixture(p.recordDir, params.Query, result); err != nil {
+			log.Printf("Search: failed to record fixture for %q: %v", params.Query, err)
+		}
+	}
+	return fromNormalized(result), nil
+}
+
+// cached returns the full result list fetched for key, if still fresh
+func (p *MetaserpProvider) cached(key string) (*SearchResponse, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.pages[key]
+	if !ok || time.Since(entry.fetched) > metaserpCacheTTL {
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// store keeps a full result list for key, starting over when the cache is full
+func (p *MetaserpProvider) store(key string, resp *SearchResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pages == nil || len(p.pages) >= metaserpCacheSize {
+		p.pages = map[string]cachedResults{}
+	}
+	p.pages[key] = cachedResults{resp: resp, fetched: time.Now()}
+}
+
+// metaserpError converts a metaserp "API error: <body>" into a StatusError with
+// the status and message from the JSON body (serper sends statusCode and
+// message, serpapi sends error). Other errors are returned as they are.
+func metaserpError(engine string, err error) error {
+	body, ok := strings.CutPrefix(err.Error(), "API error: ")
+	if !ok {
+		return err
+	}
+	statusErr := &StatusError{Provider: engine, Message: body}
+	var apiErr struct {
+		StatusCode int    `json:"statusCode"`
+		Message    string `json:"message"`
+		Error      string `json:"error"`
+	}
+	if json.Unmarshal([]byte(body), &apiErr) == nil {
+		statusErr.StatusCode = apiErr.StatusCode
+		statusErr.Message = cmp.Or(apiErr.Message, apiErr.Error, body)
+	}
+	return statusErr
+}
+
+// fromNormalized converts metaserp organic results to our response format
+func fromNormalized(result *metaserp.NormalizedSearchResult) *SearchResponse {
+	now := time.Now()
+	searchResults := make([]SearchResult, 0, len(result.OrganicResults))
+	for _, org := range result.OrganicResults {
+		link := org.Link
+		if link == "" {
+			link = org.URL
+		}
+		searchResults = append(searchResults, SearchResult{
+			Title:         org.Title,
+			URL:           link,
+			Snippet:       org.Snippet,
+			DisplayLink:   org.Domain,
+			PublishedDate: normalizeResultDate(org.Date, now),
+		})
+	}
+
+	return &SearchResponse{
+		Results: searchResults,
+		Total:   len(searchResults),
+	}
+}
+
+// recordFixture writes a normalized search result to the fixture file for query
+func recordFixture(dir, query string, result *metaserp.NormalizedSearchResult) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, FixtureKey(query)+".json"), data, 0o600)
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/grokify/metaserp"
+)
+
+// stubSERP returns a metaserp search function that serves up to total results
+// and records the number requested by each call
+func stubSERP(total int, requested *[]int) func(context.Context, metaserp.SearchParams) (*metaserp.NormalizedSearchResult, error) {
+	return func(_ context.Context, params metaserp.SearchParams) (*metaserp.NormalizedSearchResult, error) {
+		*requested = append(*requested, params.NumResults)
+		result := &metaserp.NormalizedSearchResult{}
+		for i := 1; i <= min(params.NumResults, total); i++ {
+			result.OrganicResults = append(result.OrganicResults, metaserp.OrganicResult{
+				Position: i,
+				Title:    fmt.Sprintf("Result %d", i),
+				Link:     fmt.Sprintf("https://example.org/%d", i),
+			})
+		}
+		return result, nil
+	}
+}
+
+func TestMetaserpPaging(t *testing.T) {
+	var requested []int
+	p := &MetaserpProvider{engine: "serper", search: stubSERP(35, &requested)}
+	ctx := context.Background()
+
+	tests := []struct {
+		page      int
+		wantFirst string
+		wantLen   int
+		wantCalls []int
+	}{
+		{1, "https://example.org/1", 10, []int{10}},
+		{2, "https://example.org/11", 10, []int{10, metaserpMaxResults}},
+		{3, "https://example.org/21", 10, []int{10, metaserpMaxResults}},
+		{4, "https://example.org/31", 5, []int{10, metaserpMaxResults}},
+		{5, "", 0, []int{10, metaserpMaxResults}},
+	}
+	for _, tt := range tests {
+		resp, err := p.Search(ctx, SearchParams{Query: "ev sales", NumResults: 10, Page: tt.page})
+		if err != nil {
+			t.Fatalf("page %d: %v", tt.page, err)
+		}
+		if len(resp.Results) != tt.wantLen || resp.Total != tt.wantLen {
+			t.Errorf("page %d: got %d results (total %d), want %d", tt.page, len(resp.Results), resp.Total, tt.wantLen)
+		}
+		if tt.wantLen > 0 && resp.Results[0].URL != tt.wantFirst {
+			t.Errorf("page %d starts at %s, want %s", tt.page, resp.Results[0].URL, tt.wantFirst)
+		}
+		if fmt.Sprint(requested) != fmt.Sprint(tt.wantCalls) {
+			t.Errorf("after page %d requested %v, want %v", tt.page, requested, tt.wantCalls)
+		}
+	}
+
+	// Another query fetches its own results
+	if _, err := p.Search(ctx, SearchParams{Query: "ev sales germany", NumResults: 10, Page: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 3 {
+		t.Errorf("a different query reused cached results: requested %v", requested)
+	}
+}
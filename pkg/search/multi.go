@@ -0,0 +1,274 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/stats-agent-team/pkg/canon"
+)
+
+// Search modes for multiple providers
+const (
+	ModeFailover = "failover" // Use the first provider that succeeds, in order
+	ModeFanout   = "fanout"   // Query every provider at once and merge the results
+)
+
+// quotaCooldown is how long a provider is skipped after a rate limit or quota response
+const quotaCooldown = time.Minute
+
+// rrfK is the reciprocal rank fusion constant; larger values flatten rank differences
+const rrfK = 60
+
+// FailoverProvider tries providers in order until one succeeds
+type FailoverProvider struct {
+	providers []SearchProvider
+
+	mu            sync.Mutex
+	cooldownUntil map[string]time.Time
+}
+
+// NewFailoverProvider creates a provider that fails over through providers in order
+func NewFailoverProvider(providers ...SearchProvider) *FailoverProvider {
+	return &FailoverProvider{providers: providers, cooldownUntil: map[string]time.Time{}}
+}
+
+// Name returns the provider names in failover order (e.g., "serper,serpapi")
+func (p *FailoverProvider) Name() string {
+	return joinNames(p.providers, ",")
+}
+
+// Search returns the results of the first provider that succeeds. Providers that
+// recently reported rate limits or exhausted quotas are skipped until they cool down.
+func (p *FailoverProvider) Search(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	var errs []error
+	for _, provider := range p.providers {
+		if p.coolingDown(provider.Name()) {
+			errs = append(errs, fmt.Errorf("%s: skipped after quota error", provider.Name()))
+			continue
+		}
+
+		resp, err := provider.Search(ctx, params)
+		if err == nil {
+			setProvider(resp, provider.Name())
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if IsQuotaError(err) {
+			log.Printf("Search: %s quota or rate limit reached, failing over: %v", provider.Name(), err)
+			p.startCooldown(provider.Name())
+		} else {
+			log.Printf("Search: %s failed, failing over: %v", provider.Name(), err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+	return nil, fmt.Errorf("all search providers failed: %w", errors.Join(errs...))
+}
+
+func (p *FailoverProvider) coolingDown(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Now().Before(p.cooldownUntil[name])
+}
+
+func (p *FailoverProvider) startCooldown(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cooldownUntil[name] = time.Now().Add(quotaCooldown)
+}
+
+// StatusError is an error response from a search API
+type StatusError struct {
+	Provider   string
+	StatusCode int    // HTTP status; 0 if the API reported the error without one
+	Message    string // The API's error message
+}
+
+func (e *StatusError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s API error: %s", e.Provider, e.Message)
+	}
+	return fmt.Sprintf("%s returned HTTP %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// quotaMessages are the API error messages that report a rate limit or
+// exhausted quota without a 429 or 402 status
+var quotaMessages = []string{"too many requests", "rate limit", "not enough credits", "run out of searches", "searches for the month"}
+
+// Quota reports whether the API rejected the request for a rate limit or exhausted quota
+func (e *StatusError) Quota() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusPaymentRequired:
+		return true
+	}
+	msg := strings.ToLower(e.Message)
+	for _, marker := range quotaMessages {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsQuotaError reports whether a provider error is a rate limit or exhausted
+// quota response (see StatusError.Quota)
+func IsQuotaError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Quota()
+}
+
+// FanoutProvider queries every provider concurrently and merges their results,
+// de-duplicating by canonical URL and ranking with reciprocal rank fusion
+type FanoutProvider struct {
+	providers []SearchProvider
+}
+
+// NewFanoutProvider creates a provider that merges results from all providers
+func NewFanoutProvider(providers ...SearchProvider) *FanoutProvider {
+	return &FanoutProvider{providers: providers}
+}
+
+// Name returns the merged provider names (e.g., "serper+searxng")
+func (p *FanoutProvider) Name() string {
+	return joinNames(p.providers, "+")
+}
+
+// Search queries all providers and merges their results. It fails only when every
+// provider fails.
+func (p *FanoutProvider) Search(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	responses := make([]*SearchResponse, len(p.providers))
+	errs := make([]error, len(p.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range p.providers {
+		wg.Add(1)
+		go func(i int, provider SearchProvider) {
+			defer wg.Done()
+			resp, err := provider.Search(ctx, params)
+			if err != nil {
+				log.Printf("Search: %s failed during fan-out: %v", provider.Name(), err)
+				errs[i] = fmt.Errorf("%s: %w", provider.Name(), err)
+				return
+			}
+			setProvider(resp, provider.Name())
+			responses[i] = resp
+		}(i, provider)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, resp := range responses {
+		if resp != nil {
+			succeeded++
+		}
+	}
+	if succeeded == 0 {
+		return nil, fmt.Errorf("all search providers failed: %w", errors.Join(errs...))
+	}
+
+	merged := MergeResults(responses...)
+	if params.NumResults > 0 && len(merged.Results) > params.NumResults {
+		merged.Results = merged.Results[:params.NumResults]
+		merged.Total = len(merged.Results)
+	}
+	return merged, nil
+}
+
+// MergeResults combines ranked result lists into one, de-duplicating by canonical
+// URL. Each result scores the sum of 1/(k+rank) over the lists it appears in, so
+// results ranked highly by several providers rise to the top.
+func MergeResults(responses ...*SearchResponse) *SearchResponse {
+	type entry struct {
+		result    SearchResult
+		score     float64
+		firstSeen int
+		providers []string
+		queries   []string
+	}
+
+	entries := map[string]*entry{}
+	order := 0
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		for rank, result := range resp.Results {
+			key := canon.Key(result.URL)
+			e, ok := entries[key]
+			if !ok {
+				e = &entry{result: result, firstSeen: order}
+				entries[key] = e
+				order++
+			}
+			e.score += 1.0 / float64(rrfK+rank+1)
+			for _, provider := range strings.Split(result.Provider, ",") {
+				if provider != "" && !contains(e.providers, provider) {
+					e.providers = append(e.providers, provider)
+				}
+			}
+			for _, query := range result.Queries {
+				if !contains(e.queries, query) {
+					e.queries = append(e.queries, query)
+				}
+			}
+			// Prefer the longest snippet among duplicates
+			if len(result.Snippet) > len(e.result.Snippet) {
+				e.result.Snippet = result.Snippet
+			}
+		}
+	}
+
+	merged := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		e.result.Provider = strings.Join(e.providers, ",")
+		e.result.Queries = e.queries
+		merged = append(merged, e)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].score != merged[j].score {
+			return merged[i].score > merged[j].score
+		}
+		return merged[i].firstSeen < merged[j].firstSeen
+	})
+
+	results := make([]SearchResult, len(merged))
+	for i, e := range merged {
+		results[i] = e.result
+	}
+	return &SearchResponse{Results: results, Total: len(results)}
+}
+
+// setProvider records which provider returned each result, unless already set
+func setProvider(resp *SearchResponse, name string) {
+	for i := range resp.Results {
+		if resp.Results[i].Provider == "" {
+			resp.Results[i].Provider = name
+		}
+	}
+}
+
+func joinNames(providers []SearchProvider, sep string) string {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, sep)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
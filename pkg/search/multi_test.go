@@ -0,0 +1,82 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/grokify/stats-agent-team/pkg/config"
+)
+
+func TestIsQuotaError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429 status", &StatusError{Provider: "searxng", StatusCode: 429, Message: "429 Too Many Requests"}, true},
+		{"402 status", &StatusError{Provider: "searxng", StatusCode: 402}, true},
+		{"500 status", &StatusError{Provider: "searxng", StatusCode: 500, Message: "500 Internal Server Error"}, false},
+		{"wrapped quota", fmt.Errorf("serper: %w", &StatusError{Provider: "serper", StatusCode: 400, Message: "Not enough credits"}), true},
+		{"serpapi message", &StatusError{Provider: "serpapi", Message: "Your account has run out of searches."}, true},
+		{"429 in untyped error", errors.New("failed to fetch https://example.com/report/429"), false},
+		{"credits in untyped error", errors.New("dial tcp: lookup credits.example.com: no such host"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsQuotaError(tt.err); got != tt.want {
+				t.Errorf("IsQuotaError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetaserpError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantMsg    string
+		wantQuota  bool
+	}{
+		{"serper", errors.New(`API error: {"message":"Not enough credits","statusCode":400}`), 400, "Not enough credits", true},
+		{"serper rate limit", errors.New(`API error: {"message":"Too many requests","statusCode":429}`), 429, "Too many requests", true},
+		{"serpapi", errors.New(`API error: {"error":"Invalid API key."}`), 0, "Invalid API key.", false},
+		{"plain body", errors.New("API error: Bad Gateway"), 0, "Bad Gateway", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var statusErr *StatusError
+			if !errors.As(metaserpError("serper", tt.err), &statusErr) {
+				t.Fatalf("metaserpError(%v) is not a StatusError", tt.err)
+			}
+			if statusErr.StatusCode != tt.wantStatus || statusErr.Message != tt.wantMsg {
+				t.Errorf("got status %d message %q, want %d %q", statusErr.StatusCode, statusErr.Message, tt.wantStatus, tt.wantMsg)
+			}
+			if statusErr.Quota() != tt.wantQuota {
+				t.Errorf("Quota() = %v, want %v", statusErr.Quota(), tt.wantQuota)
+			}
+		})
+	}
+
+	other := errors.New("failed to make request: context deadline exceeded")
+	if got := metaserpError("serper", other); got != other {
+		t.Errorf("metaserpError changed a non-API error: %v", got)
+	}
+}
+
+func TestNewServiceSkipsUnconfiguredProviders(t *testing.T) {
+	cfg := &config.Config{SearchProvider: "serper,searxng", SearxNGURL: "http://localhost:8888"}
+	svc, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if name := svc.provider.Name(); name != "searxng" {
+		t.Errorf("provider = %s, want searxng", name)
+	}
+
+	if _, err := NewService(&config.Config{SearchProvider: "serper,serpapi"}); err == nil {
+		t.Error("NewService succeeded with no configured provider")
+	}
+}
@@ -0,0 +1,292 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/stats-agent-team/pkg/canon"
+)
+
+// Query strategies recorded on planned queries
+const (
+	StrategyBase    = "base"    // The standard statistics query
+	StrategyYear    = "year"    // Qualified with the most recent full year
+	StrategySurvey  = "survey"  // Survey and poll results
+	StrategySite    = "site"    // Restricted to a reputable domain with site:
+	StrategyReport  = "report"  // Official and annual reports
+	StrategyCensus  = "census"  // Census and official counts
+	StrategySynonym = "synonym" // Alternative wording for statistics
+	StrategyLLM     = "llm"     // Suggested by an LLM
+)
+
+// maxConcurrentQueries bounds how many planned queries run at once
+const maxConcurrentQueries = 4
+
+// ResultsPerPage is the page size requested from providers when paging through results
+const ResultsPerPage = 10
+
+// PlannedQuery is a search query and the strategy that produced it
+type PlannedQuery struct {
+	Query    string
+	Strategy string
+}
+
+// QueryExpander suggests additional search queries for a topic
+type QueryExpander interface {
+	ExpandQueries(ctx context.Context, topic string, n int) ([]string, error)
+}
+
+// QueryPlanner turns a research topic into several search queries to improve recall
+type QueryPlanner struct {
+	maxQueries  int
+	siteFilters []string
+	expander    QueryExpander
+	now         func() time.Time
+}
+
+// NewQueryPlanner creates a planner that produces up to maxQueries queries.
+// siteFilters are domains or suffixes (e.g., "gov", "who.int") used for site:
+// queries; expander is optional and adds LLM-suggested queries.
+func NewQueryPlanner(maxQueries int, siteFilters []string, expander QueryExpander) *QueryPlanner {
+	if maxQueries <= 0 {
+		maxQueries = 1
+	}
+	return &QueryPlanner{
+		maxQueries:  maxQueries,
+		siteFilters: siteFilters,
+		expander:    expander,
+		now:         time.Now,
+	}
+}
+
+// StatisticsQuery returns the standard query for finding statistics on a topic
+func StatisticsQuery(topic string) string {
+	return fmt.Sprintf("%s statistics data research study", topic)
+}
+
+// Plan returns the queries to run for a topic, starting with the standard
+// statistics query. LLM suggestions, when available, alternate with rule-based
+// variants so both are represented when the query budget is small. params are
+// the filters the queries will run with: when they include or exclude sites,
+// the site: variants are left out, as their operators would conflict with the
+// request's.
+func (p *QueryPlanner) Plan(ctx context.Context, topic string, params SearchParams) []PlannedQuery {
+	topic = strings.TrimSpace(topic)
+	base := PlannedQuery{Query: StatisticsQuery(topic), Strategy: StrategyBase}
+	if p.maxQueries == 1 {
+		return []PlannedQuery{base}
+	}
+
+	var suggested []PlannedQuery
+	if p.expander != nil {
+		queries, err := p.expander.ExpandQueries(ctx, topic, p.maxQueries-1)
+		if err != nil {
+			log.Printf("Search: query expansion failed, using rule-based queries only: %v", err)
+		}
+		for _, q := range queries {
+			suggested = append(suggested, PlannedQuery{Query: q, Strategy: StrategyLLM})
+		}
+	}
+
+	siteFilters := p.siteFilters
+	if len(params.SiteInclude) > 0 || len(params.SiteExclude) > 0 {
+		siteFilters = nil
+	}
+	rules := p.ruleQueries(topic, siteFilters)
+	plan := []PlannedQuery{base}
+	seen := map[string]bool{normalizeQuery(base.Query): true}
+	add := func(q PlannedQuery) {
+		key := normalizeQuery(q.Query)
+		if key == "" || seen[key] || len(plan) >= p.maxQueries {
+			return
+		}
+		seen[key] = true
+		plan = append(plan, q)
+	}
+	for i := 0; len(plan) < p.maxQueries && (i < len(suggested) || i < len(rules)); i++ {
+		if i < len(suggested) {
+			add(suggested[i])
+		}
+		if i < len(rules) {
+			add(rules[i])
+		}
+	}
+	return plan
+}
+
+// ruleQueries returns the rule-based query variants in priority order, with a
+// site: variant for each of siteFilters
+func (p *QueryPlanner) ruleQueries(topic string, siteFilters []string) []PlannedQuery {
+	lastYear := strconv.Itoa(p.now().Year() - 1)
+	site := func(i int) PlannedQuery {
+		return PlannedQuery{Query: fmt.Sprintf("%s statistics site:%s", topic, siteFilters[i]), Strategy: StrategySite}
+	}
+
+	queries := []PlannedQuery{
+		{Query: fmt.Sprintf("%s statistics %s", topic, lastYear), Strategy: StrategyYear},
+		{Query: fmt.Sprintf("%s survey results", topic), Strategy: StrategySurvey},
+	}
+	if len(siteFilters) > 0 {
+		queries = append(queries, site(0))
+	}
+	queries = append(queries,
+		PlannedQuery{Query: fmt.Sprintf("%s report", topic), Strategy: StrategyReport},
+		PlannedQuery{Query: fmt.Sprintf("%s census data", topic), Strategy: StrategyCensus},
+	)
+	for i := 1; i < len(siteFilters); i++ {
+		queries = append(queries, site(i))
+	}
+	return append(queries,
+		PlannedQuery{Query: fmt.Sprintf("%s facts and figures", topic), Strategy: StrategySynonym},
+		PlannedQuery{Query: fmt.Sprintf("%s rate percentage number", topic), Strategy: StrategySynonym},
+	)
+}
+
+// normalizeQuery lowercases a query and collapses whitespace for de-duplication
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// PagingOptions control how deep SearchQueries pages through results
+type PagingOptions struct {
+	MaxPages int                     // Result pages fetched per query at most (default 1)
+	Counts   func(SearchResult) bool // Optional; only matching results count toward the target
+}
+
+// queryPages accumulates the result pages of one planned query
+type queryPages struct {
+	query PlannedQuery
+	resp  *SearchResponse
+	seen  map[string]bool
+	done  bool
+	err   error
+}
+
+// SearchQueries runs planned queries concurrently with the given filters and merges
+// their results, recording on each result the queries that found it. params.NumResults
+// is the number of unique results wanted: while the merged results fall short, the
+// next page is fetched for every query still returning new URLs, up to
+// paging.MaxPages per query. The merged results may exceed the target (the last page
+// is kept whole) and are left for the caller to trim. It fails only when every
+// query fails.
+func (s *Service) SearchQueries(ctx context.Context, queries []PlannedQuery, params SearchParams, paging PagingOptions) (*SearchResponse, error) {
+	want := params.NumResults
+	pageParams := params
+	pageParams.NumResults = ResultsPerPage
+	if want > 0 && want < ResultsPerPage {
+		pageParams.NumResults = want
+	}
+
+	states := make([]*queryPages, len(queries))
+	for i, q := range queries {
+		states[i] = &queryPages{query: q, resp: &SearchResponse{}, seen: map[string]bool{}}
+	}
+
+	merged := &SearchResponse{}
+	pages := 0
+	for page := 1; page <= max(paging.MaxPages, 1); page++ {
+		var active []*queryPages
+		for _, st := range states {
+			if !st.done {
+				active = append(active, st)
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+		if page > 1 {
+			log.Printf("Search: %d of %d results after %d pages, fetching page %d for %d queries", countResults(merged, paging.Counts), want, pages, page, len(active))
+		}
+
+		s.searchPage(ctx, active, pageParams, page)
+		pages += len(active)
+
+		responses := make([]*SearchResponse, len(states))
+		for i, st := range states {
+			responses[i] = st.resp
+		}
+		merged = MergeResults(responses...)
+		if want > 0 && countResults(merged, paging.Counts) >= want {
+			break
+		}
+	}
+
+	var errs []error
+	for _, st := range states {
+		if st.err != nil {
+			errs = append(errs, st.err)
+		}
+	}
+	if len(errs) == len(queries) {
+		return nil, fmt.Errorf("all search queries failed: %w", errors.Join(errs...))
+	}
+
+	merged.Pages = pages
+	return merged, nil
+}
+
+// searchPage fetches one result page for each query concurrently, appending the
+// URLs the query has not returned before. A query is done once a page adds nothing
+// new or fails; only a failure on the first page is reported as the query's error.
+func (s *Service) searchPage(ctx context.Context, states []*queryPages, params SearchParams, page int) {
+	sem := make(chan struct{}, maxConcurrentQueries)
+	var wg sync.WaitGroup
+	for _, st := range states {
+		wg.Add(1)
+		go func(st *queryPages) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			queryParams := params
+			queryParams.Query = st.query.Query
+			queryParams.Page = page
+			resp, err := s.SearchWithParams(ctx, queryParams)
+			if err != nil {
+				log.Printf("Search: %s query %q page %d failed: %v", st.query.Strategy, st.query.Query, page, err)
+				if page == 1 {
+					st.err = fmt.Errorf("%q: %w", st.query.Query, err)
+				}
+				st.done = true
+				return
+			}
+
+			added := 0
+			for _, r := range resp.Results {
+				key := canon.Key(r.URL)
+				if st.seen[key] {
+					continue
+				}
+				st.seen[key] = true
+				r.Queries = []string{st.query.Query}
+				st.resp.Results = append(st.resp.Results, r)
+				added++
+			}
+			st.resp.Total = len(st.resp.Results)
+			if added == 0 {
+				st.done = true
+			}
+		}(st)
+	}
+	wg.Wait()
+}
+
+// countResults returns how many results count toward the target
+func countResults(resp *SearchResponse, counts func(SearchResult) bool) int {
+	if counts == nil {
+		return len(resp.Results)
+	}
+	n := 0
+	for _, r := range resp.Results {
+		if counts(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanSiteVariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    SearchParams
+		wantSites int
+	}{
+		{"no site filters", SearchParams{}, 2},
+		{"site include", SearchParams{SiteInclude: []string{"destatis.de"}}, 0},
+		{"site exclude", SearchParams{SiteExclude: []string{"wikipedia.org"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewQueryPlanner(20, []string{"gov", "who.int"}, nil)
+			p.now = func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+			plan := p.Plan(context.Background(), "heat pump installations", tt.params)
+			if plan[0].Strategy != StrategyBase {
+				t.Errorf("first query strategy = %s, want %s", plan[0].Strategy, StrategyBase)
+			}
+			sites := 0
+			for _, q := range plan {
+				if q.Strategy == StrategySite {
+					sites++
+				}
+				if q.Strategy != StrategySite && strings.Contains(q.Query, "site:") {
+					t.Errorf("%s query %q has a site: operator", q.Strategy, q.Query)
+				}
+			}
+			if sites != tt.wantSites {
+				t.Errorf("got %d site queries, want %d: %v", sites, tt.wantSites, plan)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grokify/stats-agent-team/pkg/canon"
+)
+
+// searxngPageSize is the approximate number of results SearxNG returns per page
+const searxngPageSize = 10
+
+// searxngMaxPages is the deepest result page requested from the instance
+const searxngMaxPages = 10
+
+// SearxNGProvider searches a self-hosted SearxNG instance through its JSON API.
+// The instance must allow the json output format (search.formats in settings.yml).
+type SearxNGProvider struct {
+	baseURL    string
+	engines    []string
+	categories []string
+	client     *http.Client
+}
+
+// searxngResponse is the subset of the SearxNG JSON response we use
+type searxngResponse struct {
+	Results []struct {
+		URL           string   `json:"url"`
+		Title         string   `json:"title"`
+		Content       string   `json:"content"`
+		Engine        string   `json:"engine"`
+		Engines       []string `json:"engines"`
+		PublishedDate string   `json:"publishedDate"`
+	} `json:"results"`
+	// UnresponsiveEngines lists the engines that failed as [name, reason] pairs
+	UnresponsiveEngines [][]string `json:"unresponsive_engines"`
+}
+
+// NewSearxNGProvider creates a provider for the SearxNG instance at baseURL.
+// Empty engines or categories use the instance defaults; a nil client uses a
+// client with a 30 second timeout.
+func NewSearxNGProvider(baseURL string, engines, categories []string, client *http.Client) (*SearxNGProvider, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid SearxNG URL: %q", baseURL)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &SearxNGProvider{
+		baseURL:    u.String(),
+		engines:    engines,
+		categories: categories,
+		client:     client,
+	}, nil
+}
+
+// Name returns "searxng"
+func (p *SearxNGProvider) Name() string {
+	return "searxng"
+}
+
+// Search fetches instance pages until NumResults unique results are collected or
+// the instance runs out of results. Page n of the search starts after the instance
+// pages used for the earlier pages.
+func (p *SearxNGProvider) Search(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	numResults := params.NumResults
+	if numResults <= 0 {
+		numResults = searxngPageSize
+	}
+	pagesPerSearch := (numResults + searxngPageSize - 1) / searxngPageSize
+	first := max(params.Page-1, 0)*pagesPerSearch + 1
+
+	results := make([]SearchResult, 0, numResults)
+	seen := map[string]bool{}
+	for page := first; page < first+pagesPerSearch && page <= searxngMaxPages && len(results) < numResults; page++ {
+		pageResults, err := p.searchPage(ctx, params, page)
+		if err != nil {
+			if page > first {
+				// Keep what earlier pages returned
+				break
+			}
+			return nil, err
+		}
+
+		added := 0
+		for _, r := range pageResults {
+			key := canon.Key(r.URL)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			results = append(results, r)
+			added++
+			if len(results) >= numResults {
+				break
+			}
+		}
+		if added == 0 {
+			break
+		}
+	}
+
+	return &SearchResponse{Results: results, Total: len(results)}, nil
+}
+
+// searchPage fetches a single page of results
+func (p *SearxNGProvider) searchPage(ctx context.Context, params SearchParams, page int) ([]SearchResult, error) {
+	query := url.Values{}
+	query.Set("q", params.QueryWithOperators())
+	query.Set("format", "json")
+	query.Set("pageno", strconv.Itoa(page))
+	if params.Language != "" {
+		lang := params.Language
+		if params.Country != "" {
+			lang += "-" + strings.ToUpper(params.Country)
+		}
+		query.Set("language", lang)
+	}
+	if len(p.engines) > 0 {
+		query.Set("engines", strings.Join(p.engines, ","))
+	}
+	if len(p.categories) > 0 {
+		query.Set("categories", strings.Join(p.categories, ","))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "StatsAgentTeam/1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("searxng request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, &StatusError{Provider: "searxng", StatusCode: resp.StatusCode,
+			Message: "enable the json format in the instance's search.formats setting"}
+	case resp.StatusCode != http.StatusOK:
+		return nil, &StatusError{Provider: "searxng", StatusCode: resp.StatusCode, Message: resp.Status}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read searxng response: %w", err)
+	}
+	var data searxngResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse searxng response: %w", err)
+	}
+
+	if len(data.UnresponsiveEngines) > 0 {
+		failures := make([]string, 0, len(data.UnresponsiveEngines))
+		for _, engine := range data.UnresponsiveEngines {
+			failures = append(failures, strings.Join(engine, ": "))
+		}
+		if len(data.Results) == 0 {
+			// Reasons such as "too many requests" make this a quota error for failover
+			return nil, &StatusError{Provider: "searxng", Message: "no results, engines failed: " + strings.Join(failures, ", ")}
+		}
+		log.Printf("Search: searxng engines failed for %q: %s", params.Query, strings.Join(failures, ", "))
+	}
+
+	now := time.Now()
+	results := make([]SearchResult, 0, len(data.Results))
+	for _, r := range data.Results {
+		if r.URL == "" {
+			continue
+		}
+		domain := ""
+		if u, err := url.Parse(r.URL); err == nil {
+			domain = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+		results = append(results, SearchResult{
+			Title:         r.Title,
+			URL:           r.URL,
+			Snippet:       r.Content,
+			DisplayLink:   domain,
+			PublishedDate: normalizeResultDate(r.PublishedDate, now),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// searxngServer serves body with status for every request and records the
+// query strings it received
+func searxngServer(t *testing.T, status int, body string) (*SearxNGProvider, *[]string) {
+	t.Helper()
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Path != "/search" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := NewSearxNGProvider(srv.URL+"/", []string{"google", "bing"}, nil, srv.Client())
+	if err != nil {
+		t.Fatalf("NewSearxNGProvider: %v", err)
+	}
+	return p, &queries
+}
+
+func TestSearxNGResultMapping(t *testing.T) {
+	body := `{
+		"query": "electric vehicle sales",
+		"results": [
+			{"url": "https://www.iea.org/reports/global-ev-outlook-2024", "title": "Global EV Outlook 2024",
+			 "content": "Electric car sales neared 14 million in 2023.", "engine": "google",
+			 "engines": ["google", "bing"], "publishedDate": "2024-04-23T00:00:00"},
+			{"url": "https://iea.org/reports/global-ev-outlook-2024", "title": "Duplicate", "content": "", "engine": "bing"},
+			{"url": "", "title": "No URL", "content": "dropped"},
+			{"url": "https://example.org/ev", "title": "EV report", "content": "Sales grew 35%.", "engine": "bing"}
+		],
+		"unresponsive_engines": []
+	}`
+	p, queries := searxngServer(t, http.StatusOK, body)
+
+	resp, err := p.Search(context.Background(), SearchParams{Query: "electric vehicle sales", NumResults: 10, Language: "en", Country: "us"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	want := []SearchResult{
+		{Title: "Global EV Outlook 2024", URL: "https://www.iea.org/reports/global-ev-outlook-2024",
+			Snippet: "Electric car sales neared 14 million in 2023.", DisplayLink: "iea.org", PublishedDate: "2024-04-23"},
+		{Title: "EV report", URL: "https://example.org/ev", Snippet: "Sales grew 35%.", DisplayLink: "example.org"},
+	}
+	if len(resp.Results) != len(want) || resp.Total != len(want) {
+		t.Fatalf("got %d results (total %d), want %d: %+v", len(resp.Results), resp.Total, len(want), resp.Results)
+	}
+	for i, w := range want {
+		got := resp.Results[i]
+		if got.Title != w.Title || got.URL != w.URL || got.Snippet != w.Snippet ||
+			got.DisplayLink != w.DisplayLink || got.PublishedDate != w.PublishedDate {
+			t.Errorf("result %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if len(*queries) == 0 {
+		t.Fatal("no request reached the server")
+	}
+	q := (*queries)[0]
+	for _, param := range []string{"format=json", "pageno=1", "engines=google%2Cbing", "language=en-US", "q=electric+vehicle+sales"} {
+		if !strings.Contains(q, param) {
+			t.Errorf("query %q lacks %s", q, param)
+		}
+	}
+}
+
+func TestSearxNGErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantCode  int
+		wantMsg   string
+		wantQuota bool
+	}{
+		{"json format disabled", http.StatusForbidden, "Forbidden", http.StatusForbidden, "search.formats", false},
+		{"rate limited", http.StatusTooManyRequests, "Too Many Requests", http.StatusTooManyRequests, "429", true},
+		{"server error", http.StatusInternalServerError, "boom", http.StatusInternalServerError, "500", false},
+		{"all engines rate limited", http.StatusOK,
+			`{"results": [], "unresponsive_engines": [["google", "too many requests"], ["bing", "timeout"]]}`,
+			0, "google: too many requests, bing: timeout", true},
+		{"all engines failed", http.StatusOK,
+			`{"results": [], "unresponsive_engines": [["google", "CAPTCHA"]]}`,
+			0, "google: CAPTCHA", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := searxngServer(t, tt.status, tt.body)
+			_, err := p.Search(context.Background(), SearchParams{Query: "ev sales"})
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("Search error = %v, want a StatusError", err)
+			}
+			if statusErr.StatusCode != tt.wantCode {
+				t.Errorf("StatusCode = %d, want %d", statusErr.StatusCode, tt.wantCode)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error %q lacks %q", err, tt.wantMsg)
+			}
+			if IsQuotaError(err) != tt.wantQuota {
+				t.Errorf("IsQuotaError = %v, want %v", IsQuotaError(err), tt.wantQuota)
+			}
+		})
+	}
+}
+
+func TestSearxNGPartialEngineFailure(t *testing.T) {
+	body := `{"results": [{"url": "https://example.org/a", "title": "A", "content": "a"}],
+		"unresponsive_engines": [["bing", "timeout"]]}`
+	p, _ := searxngServer(t, http.StatusOK, body)
+	resp, err := p.Search(context.Background(), SearchParams{Query: "ev sales", NumResults: 5})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Errorf("got %d results, want 1", len(resp.Results))
+	}
+}
+
+func TestSearxNGPaging(t *testing.T) {
+	p, queries := searxngServer(t, http.StatusOK, `{"results": []}`)
+	if _, err := p.Search(context.Background(), SearchParams{Query: "ev", NumResults: 20, Page: 2}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	// 20 results take two instance pages, so search page 2 starts at instance page 3
+	if len(*queries) == 0 || !strings.Contains((*queries)[0], "pageno=3") {
+		t.Errorf("first request %v, want pageno=3", *queries)
+	}
+}
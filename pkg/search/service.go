@@ -2,105 +2,215 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
-	"github.com/grokify/metaserp"
-	"github.com/grokify/metaserp/client"
 	"github.com/grokify/stats-agent-team/pkg/config"
 )
 
-// Service provides web search capabilities using metaserp
+// SearchProvider is a search backend that returns organic web results
+type SearchProvider interface {
+	// Name identifies the provider in logs (e.g., "serper", "searxng", "fixture")
+	Name() string
+	// Search runs a single query against the backend
+	Search(ctx context.Context, params SearchParams) (*SearchResponse, error)
+}
+
+// SearchParams are the provider-independent parameters of a search
+type SearchParams struct {
+	Query           string
+	NumResults      int       // Results per page
+	Page            int       // 1-based result page (0 is the first page)
+	Language        string    // Language code (e.g., "en")
+	Country         string    // Country code (e.g., "us")
+	PublishedAfter  time.Time // Only results published on or after this date (zero for no bound)
+	PublishedBefore time.Time // Only results published before this date (zero for no bound)
+	SiteInclude     []string  // Only results from these domains
+	SiteExclude     []string  // No results from these domains
+}
+
+// Service provides web search capabilities through a pluggable provider
 type Service struct {
-	client *client.Client
+	provider SearchProvider
 }
 
 // SearchResult represents a single search result
 type SearchResult struct {
-	Title       string
-	URL         string
-	Snippet     string
-	DisplayLink string
+	Title         string
+	URL           string
+	Snippet       string
+	DisplayLink   string
+	Provider      string   // Provider(s) that returned the result (comma-separated when merged)
+	Queries       []string // Queries that found the result, when several were run
+	PublishedDate string   // Publication date (YYYY-MM-DD) when the provider reports one
 }
 
 // SearchResponse contains search results
 type SearchResponse struct {
 	Results []SearchResult
 	Total   int
+	Pages   int // Result pages fetched across all queries (set by SearchQueries)
 }
 
-// NewService creates a new search service
+// NewService creates a new search service from SEARCH_PROVIDER, which may be an
+// ordered, comma-separated list (e.g., "serper,serpapi"). Multiple providers are
+// combined according to SEARCH_MODE: failover (default) or fanout. Listed
+// providers that aren't configured (e.g., a missing API key) are skipped with a
+// warning as long as another one is.
 func NewService(cfg *config.Config) (*Service, error) {
-	var engineName string
+	var providers []SearchProvider
+	var errs []error
+	for _, name := range splitList(cfg.SearchProvider) {
+		provider, err := NewProvider(cfg, strings.ToLower(name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	switch {
+	case len(errs) == 0:
+	case len(providers) == 0:
+		return nil, errors.Join(errs...)
+	default:
+		for _, err := range errs {
+			log.Printf("Search: skipping unconfigured provider: %v", err)
+		}
+	}
+
+	switch {
+	case len(providers) == 0:
+		return nil, fmt.Errorf("no search provider configured (set SEARCH_PROVIDER)")
+	case len(providers) == 1:
+		return NewServiceWithProvider(providers[0]), nil
+	}
 
-	// Determine which search provider to use and validate API key
-	switch cfg.SearchProvider {
+	switch cfg.SearchMode {
+	case "", ModeFailover:
+		return NewServiceWithProvider(NewFailoverProvider(providers...)), nil
+	case ModeFanout:
+		return NewServiceWithProvider(NewFanoutProvider(providers...)), nil
+	default:
+		return nil, fmt.Errorf("unsupported search mode: %s (use '%s' or '%s')", cfg.SearchMode, ModeFailover, ModeFanout)
+	}
+}
+
+// NewServiceWithProvider creates a search service backed by the given provider
+func NewServiceWithProvider(provider SearchProvider) *Service {
+	return &Service{provider: provider}
+}
+
+// NewProvider creates a search provider by name
+func NewProvider(cfg *config.Config, name string) (SearchProvider, error) {
+	switch name {
 	case "serper":
 		if cfg.SerperAPIKey == "" {
 			return nil, fmt.Errorf("SERPER_API_KEY is required when using serper provider")
 		}
-		engineName = "serper"
+		return NewMetaserpProvider(name, recordDir(cfg))
 
 	case "serpapi":
 		if cfg.SerpAPIKey == "" {
 			return nil, fmt.Errorf("SERPAPI_API_KEY is required when using serpapi provider")
 		}
-		engineName = "serpapi"
+		return NewMetaserpProvider(name, recordDir(cfg))
+
+	case "searxng":
+		if cfg.SearxNGURL == "" {
+			return nil, fmt.Errorf("SEARXNG_URL is required when using searxng provider")
+		}
+		return NewSearxNGProvider(cfg.SearxNGURL, splitList(cfg.SearxNGEngines), splitList(cfg.SearxNGCategories), nil)
+
+	case "fixture":
+		if cfg.SearchFixtureDir == "" {
+			return nil, fmt.Errorf("SEARCH_FIXTURE_DIR is required when using fixture provider")
+		}
+		return NewFixtureProvider(cfg.SearchFixtureDir)
+
+	case "corpus":
+		if cfg.SearchCorpusDir == "" {
+			return nil, fmt.Errorf("SEARCH_CORPUS_DIR is required when using corpus provider")
+		}
+		return NewCorpusProvider(cfg.SearchCorpusDir)
 
 	default:
-		return nil, fmt.Errorf("unsupported search provider: %s (use 'serper' or 'serpapi')", cfg.SearchProvider)
+		return nil, fmt.Errorf("unsupported search provider: %s (use 'serper', 'serpapi', 'searxng', 'fixture' or 'corpus')", name)
 	}
+}
 
-	// Create metaserp client with specific engine
-	c, err := client.NewWithEngine(engineName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create search client: %w", err)
+// pageWindow returns the bounds of the requested page within n ranked results.
+// Providers without native paging use it to serve later pages from a longer list.
+func (p SearchParams) pageWindow(n int) (start, end int) {
+	if p.NumResults <= 0 {
+		if p.Page > 1 {
+			return n, n
+		}
+		return 0, n
+	}
+	start = min(max(p.Page-1, 0)*p.NumResults, n)
+	return start, min(start+p.NumResults, n)
+}
+
+// recordDir returns the directory live search results are recorded to, if recording is enabled
+func recordDir(cfg *config.Config) string {
+	if cfg.SearchFixtureRecord {
+		return cfg.SearchFixtureDir
+	}
+	return ""
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	return &Service{
-		client: c,
-	}, nil
+// ProviderName returns the name of the underlying search provider
+func (s *Service) ProviderName() string {
+	return s.provider.Name()
 }
 
 // Search performs a web search for the given query
 func (s *Service) Search(ctx context.Context, query string, numResults int) (*SearchResponse, error) {
-	if numResults <= 0 {
-		numResults = 10
+	return s.SearchWithParams(ctx, SearchParams{Query: query, NumResults: numResults})
+}
+
+// SearchWithParams performs a web search with locale, date and site filters. Results
+// outside the requested sites or dates are removed even when the provider could
+// not apply the filters itself.
+func (s *Service) SearchWithParams(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	if params.NumResults <= 0 {
+		params.NumResults = 10
 	}
+	if params.Language == "" {
+		params.Language = "en"
+	}
+	if params.Country == "" {
+		params.Country = "us"
+	}
+	params.Country = strings.ToLower(params.Country)
 
-	// Perform normalized search using metaserp
-	result, err := s.client.SearchNormalized(ctx, metaserp.SearchParams{
-		Query:      query,
-		NumResults: numResults,
-		Language:   "en",
-		Country:    "us",
-	})
+	resp, err := s.provider.Search(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
-
-	// Convert to our response format
-	searchResults := make([]SearchResult, 0, len(result.OrganicResults))
-
-	// Extract organic results
-	for _, org := range result.OrganicResults {
-		searchResults = append(searchResults, SearchResult{
-			Title:       org.Title,
-			URL:         org.Link,
-			Snippet:     org.Snippet,
-			DisplayLink: org.Domain,
-		})
+	setProvider(resp, s.provider.Name())
+	if dropped := filterResults(resp, params); dropped > 0 {
+		log.Printf("Search: dropped %d results outside the requested sites or dates for %q", dropped, params.Query)
 	}
-
-	return &SearchResponse{
-		Results: searchResults,
-		Total:   len(searchResults),
-	}, nil
+	return resp, nil
 }
 
 // SearchForStatistics performs a search optimized for finding statistics
 func (s *Service) SearchForStatistics(ctx context.Context, topic string, numResults int) (*SearchResponse, error) {
 	// Enhance query to find statistics from reputable sources
-	enhancedQuery := fmt.Sprintf("%s statistics data research study", topic)
-
-	return s.Search(ctx, enhancedQuery, numResults)
+	return s.Search(ctx, StatisticsQuery(topic), numResults)
 }